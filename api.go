@@ -2,14 +2,19 @@ package gowup
 
 import (
 	"bytes"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 var (
 	apiEntryPoint string = "https://api.wheresitup.com/v4"
+
+	// httpClient gives up on requests that stall. Requests made under a
+	// context give up when it's done, too.
+	httpClient = &http.Client{Timeout: time.Minute}
 )
 
 type Error struct {
@@ -32,12 +37,21 @@ type Location struct {
 }
 
 type WIU struct {
-	Client string
-	Token  string
+	Client    string
+	Token     string
+	Hooks     *Hooks
+	Telemetry *Telemetry
+	Expiry    *Expiry
 }
 
-func (api WIU) Locations() ([]Location, error) {
-	response, err := api.get("sources")
+func (api WIU) Locations() (sources []Location, err error) {
+	ctx, op := api.startOperation(context.Background(), "Locations")
+	defer func() {
+		op.set("wup.location_count", len(sources))
+		op.finish(err)
+	}()
+
+	response, err := api.get(ctx, "sources")
 	if err != nil {
 		return nil, err
 	}
@@ -55,44 +69,101 @@ func (api WIU) Locations() ([]Location, error) {
 	return sources, nil
 }
 
-func (api WIU) Jobs() (map[string]JobSummary, error) {
-	response, err := api.get("jobs")
+// Jobs lists the account's jobs. Anything the API lists under an ID that
+// isn't a job ID is skipped, so one odd entry can't hide the rest.
+func (api WIU) Jobs() (jobs map[JobID]JobSummary, err error) {
+	ctx, op := api.startOperation(context.Background(), "Jobs")
+	defer func() {
+		op.set("wup.job_count", len(jobs))
+		op.finish(err)
+	}()
+
+	response, err := api.get(ctx, "jobs")
 	if err != nil {
 		return nil, err
 	}
 
-	var jobs map[string]JobSummary
-	if err := api.parse(response, &jobs); err != nil {
+	var listed map[string]JobSummary
+	if err := api.parse(response, &listed); err != nil {
 		return nil, err
 	}
 
+	jobs = map[JobID]JobSummary{}
+	skipped := 0
+	for key, job := range listed {
+		id, err := ParseJobID(key)
+		if err != nil {
+			skipped++
+			continue
+		}
+		job.Id = id
+		jobs[id] = job
+	}
+	op.set("wup.skipped_job_count", skipped)
+
 	return jobs, nil
 }
 
-func (api WIU) Job(id string) (*Job, error) {
-	if _, err := hex.DecodeString(id); err != nil {
-		return nil, &Error{msg: "Invalid job ID '" + id + "': " + err.Error()}
-	}
+func (api WIU) Job(id JobID) (*Job, error) {
+	return api.job(context.Background(), id)
+}
 
-	response, err := api.get("jobs/" + id)
+func (api WIU) job(ctx context.Context, id JobID) (*Job, error) {
+	job, raw, err := api.fetchJob(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	job := &Job{}
-	if err := api.parse(response, job); err != nil {
-		return nil, err
-	}
+	api.checkExpiry(job, raw)
 
 	return job, nil
 }
 
-func (api WIU) Submit(req *JobRequest) (string, error) {
+// fetchJob gets a job along with the JSON it came in, for archiving.
+func (api WIU) fetchJob(ctx context.Context, id JobID) (job *Job, raw []byte, err error) {
+	ctx, op := api.startOperation(ctx, "Job")
+	op.set("wup.job_id", id.String())
+	defer func() { op.finish(err) }()
+
+	if err := api.checkJobID(id); err != nil {
+		return nil, nil, err
+	}
+
+	response, err := api.get(ctx, "jobs/"+id.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if raw, err = api.read(response); err != nil {
+		return nil, nil, err
+	}
+
+	job = &Job{}
+	if err := json.Unmarshal(raw, job); err != nil {
+		return nil, nil, api.redact(err)
+	}
+	job.Id = id
+	job.Summary.Id = id
+
+	return job, raw, nil
+}
+
+func (api WIU) Submit(req *JobRequest) (id JobID, err error) {
+	ctx, op := api.startOperation(context.Background(), "Submit")
+	defer func() {
+		op.set("wup.job_id", id.String())
+		op.finish(err)
+	}()
+
 	if req == nil {
 		return "", &Error{msg: "Nothing to submit"}
 	}
 
-	response, err := api.post("jobs", req)
+	op.set("wup.url", req.Url)
+	op.set("wup.tests", req.Tests)
+	op.set("wup.location_count", len(req.Locations))
+
+	response, err := api.post(ctx, "jobs", req)
 	if err != nil {
 		return "", err
 	}
@@ -102,14 +173,62 @@ func (api WIU) Submit(req *JobRequest) (string, error) {
 		return "", err
 	}
 
-	id, ok := posted["jobID"]
+	raw, ok := posted["jobID"]
 	if !ok {
 		return "", &Error{msg: "Submission failed"}
 	}
 
+	if id, err = ParseJobID(raw); err != nil {
+		return "", api.redact(err)
+	}
+
 	return id, nil
 }
 
+// Wait polls a job every interval until every location has finished, or ctx
+// is done. If ctx is done first, the job as it was at the last poll comes back
+// along with the context's error.
+func (api WIU) Wait(ctx context.Context, id JobID, interval time.Duration) (job *Job, err error) {
+	ctx, op := api.startOperation(ctx, "Wait")
+	op.set("wup.job_id", id.String())
+	polls := 0
+	defer func() {
+		op.set("wup.polls", polls)
+		op.finish(err)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		polls++
+		latest, err := api.job(ctx, id)
+		if err != nil && ctx.Err() != nil {
+			return job, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		job = latest
+		if job.Finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (api WIU) checkJobID(id JobID) error {
+	if err := id.Validate(); err != nil {
+		return api.redact(err)
+	}
+	return nil
+}
+
 func (api WIU) setHeaders(req *http.Request, headers map[string]string) {
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Auth", "Bearer "+api.Client+" "+api.Token)
@@ -120,42 +239,102 @@ func (api WIU) setHeaders(req *http.Request, headers map[string]string) {
 }
 
 func (api WIU) parse(response *http.Response, body interface{}) error {
-	raw, err := ioutil.ReadAll(response.Body)
+	raw, err := api.read(response)
 	if err != nil {
 		return err
 	}
-	defer response.Body.Close()
 
 	if err := json.Unmarshal(raw, body); err != nil {
-		return err
+		return api.redact(err)
 	}
 
 	return nil
 }
 
-func (api WIU) get(endpoint string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", apiEntryPoint+"/"+endpoint, nil)
+func (api WIU) read(response *http.Response) ([]byte, error) {
+	defer response.Body.Close()
+
+	raw, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, api.redact(err)
+	}
+
+	return raw, nil
+}
+
+func (api WIU) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiEntryPoint+"/"+endpoint, nil)
+	if err != nil {
+		return nil, api.redact(err)
 	}
 
 	api.setHeaders(req, nil)
 
-	return http.DefaultClient.Do(req)
+	return api.do(req, endpoint)
 }
 
-func (api WIU) post(endpoint string, data interface{}) (*http.Response, error) {
+func (api WIU) post(ctx context.Context, endpoint string, data interface{}) (*http.Response, error) {
 	body, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", apiEntryPoint+"/"+endpoint, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiEntryPoint+"/"+endpoint, bytes.NewBuffer(body))
 	if err != nil {
-		return nil, err
+		return nil, api.redact(err)
+	}
+
+	api.setHeaders(req, nil)
+
+	return api.do(req, endpoint)
+}
+
+func (api WIU) delete(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", apiEntryPoint+"/"+endpoint, nil)
+	if err != nil {
+		return nil, api.redact(err)
 	}
 
 	api.setHeaders(req, nil)
 
-	return http.DefaultClient.Do(req)
+	return api.do(req, endpoint)
+}
+
+// do sends the request and reads the whole response, so the hooks know how
+// big it was and how long it really took.
+func (api WIU) do(req *http.Request, endpoint string) (*http.Response, error) {
+	info := RequestInfo{
+		Method:   req.Method,
+		Endpoint: endpoint,
+		Url:      req.URL.String(),
+		Header:   redactHeaders(req.Header),
+		Attempt:  1,
+	}
+	if api.Hooks != nil && api.Hooks.OnRequest != nil {
+		api.Hooks.OnRequest(info)
+	}
+
+	start := time.Now()
+	response, err := httpClient.Do(req)
+
+	var raw []byte
+	if err == nil {
+		raw, err = ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	}
+	err = api.redact(err)
+
+	if api.Hooks != nil && api.Hooks.OnResponse != nil {
+		result := ResponseInfo{RequestInfo: info, Size: len(raw), Latency: time.Since(start), Err: err}
+		if response != nil {
+			result.Status = response.StatusCode
+		}
+		api.Hooks.OnResponse(result)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 }
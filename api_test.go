@@ -1,12 +1,14 @@
 package gowup
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/stretchr/testify/suite"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type ApiTest struct {
@@ -59,7 +61,7 @@ func (a *ApiTest) TestGet() {
 	endpoint := "foo"
 
 	apiEntryPoint = server.URL
-	response, err := a.api.get(endpoint)
+	response, err := a.api.get(context.Background(), endpoint)
 	server.Close()
 
 	a.Nil(err, "should not return an error")
@@ -83,7 +85,7 @@ func (a *ApiTest) TestPost() {
 	data := map[string]interface{}{"derp": "thing", "foo": []interface{}{"a", "string"}, "herp": 1}
 	marshaled, _ := json.Marshal(data)
 
-	response, err := a.api.post(endpoint, data)
+	response, err := a.api.post(context.Background(), endpoint, data)
 	a.Nil(err, "should not return an error")
 
 	body, _ := ioutil.ReadAll(response.Body)
@@ -217,6 +219,7 @@ func (a *ApiTest) TestJobs() {
 
 	job, ok := jobs["534419e98c3dcffa6170aeae"]
 	a.True(ok, "job should exist in jobs")
+	a.Equal(JobID("534419e98c3dcffa6170aeae"), job.Id, "should fill in the job id")
 
 	a.Equal("https://google.com", job.Url.String(), "should unmarshal urls")
 	a.Equal(1396972009, job.StartTime.Unix(), "should unmarshal time")
@@ -224,9 +227,31 @@ func (a *ApiTest) TestJobs() {
 	a.Equal("trace", job.Services[0].Tests[2], "should unmarshal services")
 }
 
+func (a *ApiTest) TestJobsWithBadIds() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+		    "534419e98c3dcffa6170aeae": {"url": "https://google.com"},
+		    "herpderp": {"url": "https://example.com"}
+		}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	jobs, err := a.api.Jobs()
+	a.NoError(err, "should not fail the whole listing")
+	a.Equal(1, len(jobs), "should skip ids that aren't job ids")
+	a.Equal("https://google.com", jobs["534419e98c3dcffa6170aeae"].Url.String())
+}
+
 func (a *ApiTest) TestBadJobId() {
 	_, err := a.api.Job("herpderp")
 	a.Error(err, "should reject bad job ids")
+
+	_, err = a.api.Job("")
+	a.Error(err, "should reject empty job ids")
+
+	_, err = a.api.Job("534419E98C3DCFFA6170AEAE")
+	a.Error(err, "should reject job ids that weren't parsed")
 }
 
 func (a *ApiTest) TestJobWithSummaryOnly() {
@@ -247,9 +272,11 @@ func (a *ApiTest) TestJobWithSummaryOnly() {
 	defer server.Close()
 	apiEntryPoint = server.URL
 
-	job, err := a.api.Job("aa")
+	job, err := a.api.Job("534419e98c3dcffa6170aeae")
 	a.NoError(err, "should not return an error")
 
+	a.Equal(JobID("534419e98c3dcffa6170aeae"), job.Id, "should fill in the job id")
+	a.Equal(JobID("534419e98c3dcffa6170aeae"), job.Summary.Id, "should fill in the summary's job id")
 	a.Equal(1404053589, job.Summary.StartTime.Unix(), "should decode the start time")
 	a.Equal(1405263189, job.Summary.ExpireTime.Unix(), "should decode the expiration time")
 	a.Equal("107.4.56.245", job.Summary.Ip, "should decode the ip address")
@@ -286,7 +313,7 @@ func (a *ApiTest) TestJobDetails() {
 	defer server.Close()
 	apiEntryPoint = server.URL
 
-	job, err := a.api.Job("aa")
+	job, err := a.api.Job("534419e98c3dcffa6170aeae")
 	a.NoError(err, "should not return an error")
 	a.Equal(
 		map[string]interface{}{"some": "random content"},
@@ -364,7 +391,7 @@ func (a *ApiTest) TestMissingJobID() {
 
 func (a *ApiTest) TestJobID() {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		data, _ := json.Marshal(map[string]string{"jobID": "herpderp"})
+		data, _ := json.Marshal(map[string]string{"jobID": "534419E98C3DCFFA6170AEAE"})
 		w.Write(data)
 	}))
 	defer server.Close()
@@ -372,7 +399,72 @@ func (a *ApiTest) TestJobID() {
 
 	id, err := a.api.Submit(&JobRequest{})
 	a.NoError(err, "should not return an error")
-	a.Equal("herpderp", id, "should match the job id sent")
+	a.Equal(JobID("534419e98c3dcffa6170aeae"), id, "should match the job id sent")
+}
+
+func (a *ApiTest) TestBadSubmittedJobID() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := json.Marshal(map[string]string{"jobID": "herpderp"})
+		w.Write(data)
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	_, err := a.api.Submit(&JobRequest{})
+	a.Error(err, "should reject a bad job id from the API")
+}
+
+func (a *ApiTest) TestWait() {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Write([]byte(`{"response": {"complete": [], "error": [], "in_progress": {"denver": {"ping": {}}}}}`))
+			return
+		}
+		w.Write([]byte(`{"response": {"complete": {"denver": {"ping": {"summary": {"avg": 12}}}}, "error": [], "in_progress": []}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	job, err := a.api.Wait(context.Background(), "534419e98c3dcffa6170aeae", time.Millisecond)
+	a.NoError(err, "should not return an error")
+	a.Equal(3, calls, "should poll until the job is finished")
+	a.True(job.Finished(), "should return the finished job")
+}
+
+func (a *ApiTest) TestWaitTimeout() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": {"complete": [], "error": [], "in_progress": {"denver": {"ping": {}}}}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	job, err := a.api.Wait(ctx, "534419e98c3dcffa6170aeae", time.Millisecond)
+	a.Equal(context.DeadlineExceeded, err, "should stop waiting when the context is done")
+	a.False(job.Finished(), "should return the unfinished job")
+}
+
+func (a *ApiTest) TestWaitStalled() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := a.api.Wait(ctx, "534419e98c3dcffa6170aeae", time.Millisecond)
+	a.Equal(context.DeadlineExceeded, err)
+	a.Less(time.Since(start), time.Second, "should give up on a request that stalls when the context is done")
 }
 
 // todo: test error handling for get
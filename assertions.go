@@ -0,0 +1,337 @@
+package gowup
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Assertion is one check against the results of a job, e.g. "ping loss < 5
+// from every location in Europe":
+//
+//	test: ping
+//	metric: loss
+//	op: "<"
+//	value: 5
+//	continents: [Europe]
+//
+// Metrics are loss, latency, min and max for ping; status, time, ttfb and
+// cert_days (days until the certificate expires) for http; and answers for
+// dig. Ops are <, <=, >, >=, ==, != and contains. For example, to make sure
+// no location is being served a certificate that runs out within a month:
+//
+//	test: http
+//	metric: cert_days
+//	op: ">="
+//	value: 30
+//
+// Locations and Continents narrow down which locations in the job the
+// assertion applies to; with neither, it applies to all of them. Quorum is
+// the percentage of those locations that must pass, 100 if it's left out.
+type Assertion struct {
+	Name       string      `json:"name" yaml:"name"`
+	Test       string      `json:"test" yaml:"test"`
+	Metric     string      `json:"metric" yaml:"metric"`
+	Op         string      `json:"op" yaml:"op"`
+	Value      interface{} `json:"value" yaml:"value"`
+	Locations  []string    `json:"locations" yaml:"locations"`
+	Continents []string    `json:"continents" yaml:"continents"`
+	Quorum     float64     `json:"quorum" yaml:"quorum"`
+}
+
+// AssertionResult is the outcome of one assertion, with the details for each
+// location it applied to.
+type AssertionResult struct {
+	Assertion Assertion
+	Passed    bool
+	Locations map[string]LocationResult
+}
+
+// LocationResult is the outcome of one assertion at one location. Actual is
+// the value that was compared; Reason explains a failure.
+type LocationResult struct {
+	Passed bool
+	Actual string
+	Reason string
+}
+
+// LoadAssertions reads a list of assertions from a YAML or JSON file.
+func LoadAssertions(path string) ([]Assertion, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAssertions(raw)
+}
+
+// ParseAssertions decodes a list of assertions. JSON is valid YAML, so this
+// handles both.
+func ParseAssertions(raw []byte) ([]Assertion, error) {
+	var assertions []Assertion
+	if err := yaml.Unmarshal(raw, &assertions); err != nil {
+		return nil, &Error{msg: "Invalid assertions: " + err.Error()}
+	}
+
+	for _, a := range assertions {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return assertions, nil
+}
+
+func (a Assertion) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s %s %s %v", a.Test, a.Metric, a.Op, a.Value)
+}
+
+// Validate makes sure the test, metric and op go together.
+func (a Assertion) Validate() error {
+	metrics := map[string][]string{
+		"ping": {"loss", "latency", "min", "max"},
+		"http": {"status", "time", "ttfb", "cert_days"},
+		"dig":  {"answers"},
+	}
+
+	valid, ok := metrics[a.Test]
+	if !ok {
+		return &Error{msg: "Unknown test '" + a.Test + "' in assertion '" + a.String() + "'"}
+	}
+	if !contains(valid, a.Metric) {
+		return &Error{msg: "Unknown metric '" + a.Metric + "' in assertion '" + a.String() + "'"}
+	}
+
+	switch a.Op {
+	case "<", "<=", ">", ">=":
+		if _, err := toFloat(a.Value); err != nil || a.Metric == "answers" {
+			return &Error{msg: "Assertion '" + a.String() + "' needs a numeric value"}
+		}
+	case "==", "!=", "contains":
+	default:
+		return &Error{msg: "Unknown op '" + a.Op + "' in assertion '" + a.String() + "'"}
+	}
+
+	if a.Quorum < 0 || a.Quorum > 100 {
+		return &Error{msg: "Quorum must be between 0 and 100 in assertion '" + a.String() + "'"}
+	}
+
+	return nil
+}
+
+// Check evaluates every assertion against the job. The location catalog from
+// Locations() is only needed for assertions that filter by continent.
+func (j Job) Check(assertions []Assertion, locations []Location) ([]AssertionResult, error) {
+	results := make([]AssertionResult, 0, len(assertions))
+	for _, a := range assertions {
+		result, err := a.Evaluate(j, locations)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Evaluate checks the assertion at every location it applies to.
+func (a Assertion) Evaluate(job Job, locations []Location) (AssertionResult, error) {
+	if err := a.Validate(); err != nil {
+		return AssertionResult{}, err
+	}
+
+	continents := map[string]string{}
+	for _, location := range locations {
+		continents[location.Name] = location.Continent
+	}
+
+	result := AssertionResult{Assertion: a, Locations: map[string]LocationResult{}}
+	passed := 0
+	for _, city := range job.locationNames() {
+		if len(a.Locations) > 0 && !contains(a.Locations, city) {
+			continue
+		}
+		if len(a.Continents) > 0 && !contains(a.Continents, continents[city]) {
+			continue
+		}
+
+		outcome := a.evaluateAt(job, city)
+		if outcome.Passed {
+			passed++
+		}
+		result.Locations[city] = outcome
+	}
+
+	quorum := a.Quorum
+	if quorum == 0 {
+		quorum = 100
+	}
+
+	total := len(result.Locations)
+	result.Passed = total > 0 && float64(passed)*100 >= quorum*float64(total)
+
+	return result, nil
+}
+
+func (a Assertion) evaluateAt(job Job, city string) LocationResult {
+	if _, ok := job.Details.Error[city][a.Test]; ok {
+		return LocationResult{Reason: a.Test + " failed"}
+	}
+	if _, ok := job.Details.NotDone[city][a.Test]; ok {
+		return LocationResult{Reason: a.Test + " is still in progress"}
+	}
+
+	summary, ok := job.Details.Done[city][a.Test]
+	if !ok || summary == nil {
+		return LocationResult{Reason: "no " + a.Test + " result"}
+	}
+
+	actual, err := a.metric(summary)
+	if err != nil {
+		return LocationResult{Reason: err.Error()}
+	}
+
+	passed, err := compare(actual, a.Op, a.Value)
+	if err != nil {
+		return LocationResult{Actual: format(actual), Reason: err.Error()}
+	}
+
+	outcome := LocationResult{Passed: passed, Actual: format(actual)}
+	if !passed {
+		outcome.Reason = fmt.Sprintf("%s is %s, expected %s %v", a.Metric, outcome.Actual, a.Op, a.Value)
+	}
+
+	return outcome
+}
+
+// metric pulls the value being checked out of a test summary: a float64 for
+// numeric metrics and a []string for dig answers.
+func (a Assertion) metric(summary interface{}) (interface{}, error) {
+	switch a.Test {
+	case "ping":
+		var ping PingResult
+		if err := decodeSummary(summary, &ping); err != nil {
+			return nil, err
+		}
+		switch a.Metric {
+		case "loss":
+			return ping.Loss, nil
+		case "latency":
+			return ping.Avg, nil
+		case "min":
+			return ping.Min, nil
+		case "max":
+			return ping.Max, nil
+		}
+	case "http":
+		var http HttpResult
+		if err := decodeSummary(summary, &http); err != nil {
+			return nil, err
+		}
+		final := http.Final()
+		if final == nil {
+			return nil, &Error{msg: "no http responses"}
+		}
+		switch a.Metric {
+		case "status":
+			return float64(final.Status), nil
+		case "time":
+			return http.Time(), nil
+		case "ttfb":
+			if final.Timing == nil {
+				return nil, &Error{msg: "no http timing"}
+			}
+			return final.Timing.Ttfb, nil
+		case "cert_days":
+			if final.Tls == nil {
+				return nil, &Error{msg: "no certificate"}
+			}
+			return float64(CertStatus{Left: time.Until(final.Tls.Expiry.Time)}.Days()), nil
+		}
+	case "dig":
+		var dig DigResult
+		if err := decodeSummary(summary, &dig); err != nil {
+			return nil, err
+		}
+		return dig.Data(), nil
+	}
+
+	return nil, &Error{msg: "Unknown metric '" + a.Metric + "'"}
+}
+
+func compare(actual interface{}, op string, expected interface{}) (bool, error) {
+	if answers, ok := actual.([]string); ok {
+		want := fmt.Sprint(expected)
+		switch op {
+		case "contains":
+			return contains(answers, want), nil
+		case "==":
+			return len(answers) == 1 && answers[0] == want, nil
+		case "!=":
+			return !contains(answers, want), nil
+		}
+		return false, &Error{msg: "Can't compare answers with " + op}
+	}
+
+	value := actual.(float64)
+	want, err := toFloat(expected)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "<":
+		return value < want, nil
+	case "<=":
+		return value <= want, nil
+	case ">":
+		return value > want, nil
+	case ">=":
+		return value >= want, nil
+	case "==", "contains":
+		return value == want, nil
+	case "!=":
+		return value != want, nil
+	}
+
+	return false, &Error{msg: "Unknown op '" + op + "'"}
+}
+
+// toFloat copes with whatever the YAML or JSON decoder made of a number.
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	}
+
+	return 0, &Error{msg: fmt.Sprintf("Expected a number, got %v", value)}
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package gowup
+
+import (
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type AssertionsTest struct {
+	suite.Suite
+	job       Job
+	locations []Location
+}
+
+func TestAssertions(t *testing.T) {
+	suite.Run(t, new(AssertionsTest))
+}
+
+func (a *AssertionsTest) SetupTest() {
+	a.job = Job{Details: JobDetails{
+		Done: JobDetail{
+			"riga": {
+				"ping": map[string]interface{}{"loss": 0, "avg": 30},
+				"http": []interface{}{map[string]interface{}{"status": 200, "time": 80}},
+				"dig":  map[string]interface{}{"answers": []interface{}{map[string]interface{}{"type": "A", "data": "93.184.216.34"}}},
+			},
+			"london": {
+				"ping": map[string]interface{}{"loss": 25, "avg": 20},
+				"http": []interface{}{map[string]interface{}{"status": 503, "time": 10}},
+			},
+			"denver": {
+				"ping": map[string]interface{}{"loss": 0, "avg": 50},
+				"http": []interface{}{
+					map[string]interface{}{"status": 301, "time": 40},
+					map[string]interface{}{"status": 200, "time": 60},
+				},
+				"dig": map[string]interface{}{"answers": []interface{}{map[string]interface{}{"type": "A", "data": "10.0.0.1"}}},
+			},
+		},
+		NotDone: JobDetail{"sydney": {"http": nil}},
+	}}
+
+	a.locations = []Location{
+		{Name: "riga", Continent: "Europe"},
+		{Name: "london", Continent: "Europe"},
+		{Name: "denver", Continent: "North America"},
+		{Name: "sydney", Continent: "Oceania"},
+	}
+}
+
+func (a *AssertionsTest) TestContinentFilter() {
+	result, err := Assertion{Test: "ping", Metric: "loss", Op: "<", Value: 5, Continents: []string{"Europe"}}.Evaluate(a.job, a.locations)
+	a.NoError(err, "should not return an error")
+	a.False(result.Passed, "should fail if any european location fails")
+	a.Equal(2, len(result.Locations), "should only check european locations")
+	a.True(result.Locations["riga"].Passed)
+	a.False(result.Locations["london"].Passed)
+	a.Equal("25", result.Locations["london"].Actual, "should report the actual value")
+}
+
+func (a *AssertionsTest) TestQuorum() {
+	assertion := Assertion{Test: "http", Metric: "status", Op: "==", Value: 200, Quorum: 50}
+
+	result, err := assertion.Evaluate(a.job, a.locations)
+	a.NoError(err, "should not return an error")
+	a.True(result.Passed, "should pass when enough locations pass")
+	a.Equal("http is still in progress", result.Locations["sydney"].Reason, "should fail unfinished locations")
+	a.True(result.Locations["denver"].Passed, "should check the final response")
+
+	assertion.Quorum = 90
+	result, _ = assertion.Evaluate(a.job, a.locations)
+	a.False(result.Passed, "should fail when too few locations pass")
+}
+
+func (a *AssertionsTest) TestDigAnswers() {
+	result, err := Assertion{Test: "dig", Metric: "answers", Op: "contains", Value: "93.184.216.34"}.Evaluate(a.job, a.locations)
+	a.NoError(err, "should not return an error")
+	a.True(result.Locations["riga"].Passed)
+	a.False(result.Locations["denver"].Passed)
+	a.Equal("no dig result", result.Locations["london"].Reason, "should fail locations without the test")
+}
+
+func (a *AssertionsTest) TestLocationFilter() {
+	result, _ := Assertion{Test: "ping", Metric: "latency", Op: "<=", Value: "50", Locations: []string{"denver"}}.Evaluate(a.job, nil)
+	a.True(result.Passed)
+	a.Equal(1, len(result.Locations), "should only check the listed locations")
+}
+
+func (a *AssertionsTest) TestNoMatchingLocations() {
+	result, _ := Assertion{Test: "ping", Metric: "loss", Op: "<", Value: 5, Continents: []string{"Antarctica"}}.Evaluate(a.job, a.locations)
+	a.False(result.Passed, "should not pass without any locations to check")
+}
+
+func (a *AssertionsTest) TestMissingSummary() {
+	a.job.Details.Done["london"]["ping"] = nil
+
+	result, _ := Assertion{Test: "ping", Metric: "loss", Op: "<", Value: 5, Locations: []string{"london"}}.Evaluate(a.job, nil)
+	a.False(result.Passed, "should not pass without a summary")
+	a.Equal("no ping result", result.Locations["london"].Reason)
+}
+
+func (a *AssertionsTest) TestValidate() {
+	a.Error(Assertion{Test: "fast", Metric: "loss", Op: "<", Value: 5}.Validate(), "should reject unknown tests")
+	a.Error(Assertion{Test: "ping", Metric: "status", Op: "<", Value: 5}.Validate(), "should reject unknown metrics")
+	a.Error(Assertion{Test: "ping", Metric: "loss", Op: "~", Value: 5}.Validate(), "should reject unknown ops")
+	a.Error(Assertion{Test: "ping", Metric: "loss", Op: "<", Value: "lots"}.Validate(), "should need numbers for numeric ops")
+	a.Error(Assertion{Test: "ping", Metric: "loss", Op: "<", Value: 5, Quorum: 101}.Validate(), "should reject quorums over 100")
+	a.NoError(Assertion{Test: "ping", Metric: "loss", Op: "<", Value: "5%"}.Validate(), "should accept percentages")
+}
+
+func (a *AssertionsTest) TestParseYaml() {
+	assertions, err := ParseAssertions([]byte(`
+- name: europe loss
+  test: ping
+  metric: loss
+  op: "<"
+  value: 5
+  continents: [Europe]
+- test: http
+  metric: status
+  op: "=="
+  value: 200
+  quorum: 90
+`))
+	a.NoError(err, "should not return an error")
+	a.Equal(2, len(assertions))
+	a.Equal("europe loss", assertions[0].String())
+	a.Equal([]string{"Europe"}, assertions[0].Continents)
+	a.Equal(float64(90), assertions[1].Quorum)
+}
+
+func (a *AssertionsTest) TestLoadJson() {
+	path := filepath.Join(a.T().TempDir(), "assertions.json")
+	ioutil.WriteFile(path, []byte(`[{"test": "dig", "metric": "answers", "op": "contains", "value": "93.184.216.34"}]`), 0600)
+
+	assertions, err := LoadAssertions(path)
+	a.NoError(err, "should not return an error")
+
+	results, err := a.job.Check(assertions, a.locations)
+	a.NoError(err, "should not return an error")
+	a.Equal(1, len(results))
+	a.False(results[0].Passed)
+}
+
+func (a *AssertionsTest) TestLoadInvalid() {
+	_, err := ParseAssertions([]byte(`[{"test": "ping", "metric": "loss", "op": "~"}]`))
+	a.Error(err, "should validate while parsing")
+
+	_, err = LoadAssertions(filepath.Join(os.TempDir(), "nope", "missing.yaml"))
+	a.Error(err, "should fail on missing files")
+}
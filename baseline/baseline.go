@@ -0,0 +1,281 @@
+// Package baseline learns what's normal for each target, location and test
+// from past jobs, and scores new results against it. Across a hundred
+// locations no one threshold fits them all; a baseline per location does.
+package baseline
+
+import (
+	"encoding/json"
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/internal/atomicfile"
+	"github.com/ellotheth/gowup/schedule"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Key identifies one series of results.
+type Key struct {
+	Target   string `json:"target"`
+	Location string `json:"location"`
+	Test     string `json:"test"`
+}
+
+// Series is the history behind one key: an exponentially weighted mean and
+// variance over everything seen, and the most recent values for the median
+// and MAD.
+type Series struct {
+	Key    Key       `json:"key"`
+	Count  int       `json:"count"`
+	Ewma   float64   `json:"ewma"`
+	Ewmv   float64   `json:"ewmv"`
+	Recent []float64 `json:"recent"`
+}
+
+// Score is how one new result compares to its baseline. Z is the robust
+// z-score from the median and MAD, or the EWMA z-score if the MAD is zero;
+// EwmaZ is always the EWMA one. Anomalous is only ever set once the series
+// has enough samples to go on.
+type Score struct {
+	Key
+	Value     float64
+	Samples   int
+	Ewma      float64
+	Median    float64
+	Mad       float64
+	Z         float64
+	EwmaZ     float64
+	Anomalous bool
+}
+
+// Baseline keeps a series per key. Use New for the defaults: Alpha 0.2,
+// Window 50, MinSamples 10 and Threshold 3.5, the usual cut-off for robust
+// z-scores.
+type Baseline struct {
+	// Alpha is the weight of each new value in the EWMA.
+	Alpha float64
+	// Window is how many recent values the median and MAD are taken over.
+	Window int
+	// MinSamples is how many values a series needs before anything in it
+	// counts as anomalous.
+	MinSamples int
+	// Threshold is how far (in z) a value has to be from normal to be
+	// anomalous, either way.
+	Threshold float64
+
+	mu     sync.Mutex
+	series map[Key]*Series
+}
+
+func New() *Baseline {
+	return &Baseline{Alpha: 0.2, Window: 50, MinSamples: 10, Threshold: 3.5, series: map[Key]*Series{}}
+}
+
+// Load reads a baseline saved with Save, with the default settings. A
+// missing file is an empty baseline.
+func Load(path string) (*Baseline, error) {
+	b := New()
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var series []*Series
+	if err := json.Unmarshal(raw, &series); err != nil {
+		return nil, err
+	}
+	for _, s := range series {
+		b.series[s.Key] = s
+	}
+
+	return b, nil
+}
+
+// Save writes every series to path as JSON.
+func (b *Baseline) Save(path string) error {
+	b.mu.Lock()
+	series := make([]*Series, 0, len(b.series))
+	for _, s := range b.series {
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool { return less(series[i].Key, series[j].Key) })
+
+	raw, err := json.MarshalIndent(series, "", "    ")
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(path, raw, 0600)
+}
+
+// Series returns a copy of the history for key.
+func (b *Baseline) Series(key Key) (Series, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.series[key]
+	if !ok {
+		return Series{}, false
+	}
+	copied := *s
+	copied.Recent = append([]float64{}, s.Recent...)
+	return copied, true
+}
+
+// Add feeds the results of a job into the baseline.
+func (b *Baseline) Add(job *gowup.Job) error {
+	values, err := Values(job)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key, value := range values {
+		b.add(key, value)
+	}
+
+	return nil
+}
+
+// Score compares the results of a job to the baseline, without adding them.
+// Scores are sorted by target, location and test.
+func (b *Baseline) Score(job *gowup.Job) ([]Score, error) {
+	values, err := Values(job)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.score(values), nil
+}
+
+// Observe scores a job and then adds it, so each result is judged against
+// what came before it.
+func (b *Baseline) Observe(job *gowup.Job) ([]Score, error) {
+	values, err := Values(job)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	scores := b.score(values)
+	for key, value := range values {
+		b.add(key, value)
+	}
+
+	return scores, nil
+}
+
+// Sink observes every job the scheduler finishes and calls alert with the
+// anomalous scores, if there are any.
+func (b *Baseline) Sink(alert func(check schedule.Check, id gowup.JobID, anomalies []Score)) schedule.Sink {
+	return schedule.SinkFunc(func(check schedule.Check, id gowup.JobID, job *gowup.Job) error {
+		scores, err := b.Observe(job)
+		if err != nil {
+			return err
+		}
+
+		anomalies := []Score{}
+		for _, score := range scores {
+			if score.Anomalous {
+				anomalies = append(anomalies, score)
+			}
+		}
+		if len(anomalies) > 0 {
+			alert(check, id, anomalies)
+		}
+
+		return nil
+	})
+}
+
+func (b *Baseline) add(key Key, value float64) {
+	if b.series == nil {
+		b.series = map[Key]*Series{}
+	}
+
+	s, ok := b.series[key]
+	if !ok {
+		s = &Series{Key: key, Ewma: value}
+		b.series[key] = s
+	}
+
+	// West's incremental EWMA and variance
+	diff := value - s.Ewma
+	increment := b.Alpha * diff
+	s.Ewma += increment
+	s.Ewmv = (1 - b.Alpha) * (s.Ewmv + diff*increment)
+
+	s.Count++
+	s.Recent = append(s.Recent, value)
+	if len(s.Recent) > b.Window {
+		s.Recent = s.Recent[len(s.Recent)-b.Window:]
+	}
+}
+
+// madFloor is the smallest MAD a series is scored with, as a fraction of its
+// median.
+const madFloor = 0.05
+
+func (b *Baseline) score(values map[Key]float64) []Score {
+	scores := make([]Score, 0, len(values))
+	for key, value := range values {
+		score := Score{Key: key, Value: value}
+
+		if s, ok := b.series[key]; ok {
+			score.Samples = s.Count
+			score.Ewma = s.Ewma
+			score.Median = gowup.Median(s.Recent)
+
+			deviations := make([]float64, len(s.Recent))
+			for i, recent := range s.Recent {
+				deviations[i] = math.Abs(recent - score.Median)
+			}
+			score.Mad = gowup.Median(deviations)
+
+			if s.Ewmv > 0 {
+				score.EwmaZ = (value - s.Ewma) / math.Sqrt(s.Ewmv)
+			}
+
+			// a series of identical values (whole millisecond pings, say) has
+			// no spread at all, so the MAD gets a floor relative to the median
+			spread := math.Max(score.Mad, madFloor*math.Abs(score.Median))
+
+			// 0.6745 scales the MAD to match the standard deviation of a
+			// normal distribution
+			score.Z = score.EwmaZ
+			if spread > 0 {
+				score.Z = 0.6745 * (value - score.Median) / spread
+			}
+
+			// with nothing to scale by, any change at all is out of the ordinary
+			flat := spread == 0 && s.Ewmv == 0 && value != score.Median
+			score.Anomalous = score.Samples >= b.MinSamples && (flat || math.Abs(score.Z) > b.Threshold)
+		}
+
+		scores = append(scores, score)
+	}
+
+	sort.Slice(scores, func(i, j int) bool { return less(scores[i].Key, scores[j].Key) })
+	return scores
+}
+
+func less(a, b Key) bool {
+	if a.Target != b.Target {
+		return a.Target < b.Target
+	}
+	if a.Location != b.Location {
+		return a.Location < b.Location
+	}
+	return a.Test < b.Test
+}
@@ -0,0 +1,160 @@
+package baseline
+
+import (
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/schedule"
+	"github.com/stretchr/testify/suite"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+// pinged is a job where each location pinged google.com with the given
+// average
+func pinged(averages map[string]float64) *gowup.Job {
+	target, _ := url.Parse("https://google.com")
+	job := &gowup.Job{Summary: gowup.JobSummary{Url: gowup.Url{URL: target}}, Details: gowup.JobDetails{Done: gowup.JobDetail{}}}
+	for city, avg := range averages {
+		job.Details.Done[city] = map[string]interface{}{"ping": map[string]interface{}{"avg": avg, "loss": 0}}
+	}
+	return job
+}
+
+var denver = Key{Target: "https://google.com", Location: "denver", Test: "ping"}
+
+type BaselineTest struct {
+	suite.Suite
+	baseline *Baseline
+}
+
+func TestBaseline(t *testing.T) {
+	suite.Run(t, new(BaselineTest))
+}
+
+func (b *BaselineTest) SetupTest() {
+	b.baseline = New()
+	for _, avg := range []float64{10, 11, 9, 10, 12, 10, 9, 11, 10, 10} {
+		b.NoError(b.baseline.Add(pinged(map[string]float64{"denver": avg, "riga": avg * 4})))
+	}
+}
+
+func (b *BaselineTest) TestSeries() {
+	s, ok := b.baseline.Series(denver)
+	b.True(ok)
+	b.Equal(10, s.Count)
+	b.Equal(10, len(s.Recent))
+	b.InDelta(10, s.Ewma, 1, "should track the mean")
+	b.True(s.Ewmv > 0)
+
+	_, ok = b.baseline.Series(Key{Target: "nope"})
+	b.False(ok)
+}
+
+func (b *BaselineTest) TestScore() {
+	scores, err := b.baseline.Score(pinged(map[string]float64{"denver": 30, "riga": 41, "tokyo": 100}))
+	b.NoError(err)
+	b.Equal(3, len(scores))
+
+	b.Equal(denver, scores[0].Key, "should sort by location")
+	b.Equal(float64(10), scores[0].Median)
+	b.Equal(0.5, scores[0].Mad)
+	b.InDelta(0.6745*20/0.5, scores[0].Z, 0.0001, "should use the robust z-score")
+	b.True(scores[0].EwmaZ > 3)
+	b.True(scores[0].Anomalous, "should flag results way out of line")
+
+	b.False(scores[1].Anomalous, "should judge each location against its own baseline")
+
+	b.Equal("tokyo", scores[2].Location)
+	b.Equal(0, scores[2].Samples)
+	b.False(scores[2].Anomalous, "should not flag locations it knows nothing about")
+
+	s, _ := b.baseline.Series(denver)
+	b.Equal(10, s.Count, "should not add scored results")
+}
+
+func (b *BaselineTest) TestMinSamples() {
+	b.baseline.MinSamples = 11
+	scores, _ := b.baseline.Score(pinged(map[string]float64{"denver": 30}))
+	b.False(scores[0].Anomalous, "should wait for enough samples")
+	b.True(scores[0].Z > b.baseline.Threshold)
+}
+
+func (b *BaselineTest) TestFlatSeries() {
+	flat := New()
+	for i := 0; i < 10; i++ {
+		flat.Add(pinged(map[string]float64{"denver": 10}))
+	}
+
+	scores, _ := flat.Score(pinged(map[string]float64{"denver": 10}))
+	b.Equal(float64(0), scores[0].Z, "should cope with no spread at all")
+	b.False(scores[0].Anomalous)
+
+	scores, _ = flat.Score(pinged(map[string]float64{"denver": 11}))
+	b.False(scores[0].Anomalous, "should allow a little jitter")
+
+	scores, _ = flat.Score(pinged(map[string]float64{"denver": 2000}))
+	b.True(scores[0].Anomalous, "should flag a spike from no spread")
+	b.True(scores[0].Z > 3.5)
+}
+
+func (b *BaselineTest) TestFlatZeroSeries() {
+	flat := New()
+	for i := 0; i < 10; i++ {
+		flat.Add(pinged(map[string]float64{"denver": 0}))
+	}
+
+	scores, _ := flat.Score(pinged(map[string]float64{"denver": 0}))
+	b.False(scores[0].Anomalous)
+
+	scores, _ = flat.Score(pinged(map[string]float64{"denver": 5}))
+	b.True(scores[0].Anomalous, "should flag any change from a series of zeroes")
+}
+
+func (b *BaselineTest) TestObserve() {
+	scores, err := b.baseline.Observe(pinged(map[string]float64{"denver": 30}))
+	b.NoError(err)
+	b.True(scores[0].Anomalous, "should score before adding")
+
+	s, _ := b.baseline.Series(denver)
+	b.Equal(11, s.Count, "should add observed results")
+}
+
+func (b *BaselineTest) TestWindow() {
+	b.baseline.Window = 3
+	b.baseline.Add(pinged(map[string]float64{"denver": 50}))
+
+	s, _ := b.baseline.Series(denver)
+	b.Equal([]float64{10, 10, 50}, s.Recent, "should only keep the window")
+	b.Equal(11, s.Count)
+}
+
+func (b *BaselineTest) TestSaveAndLoad() {
+	path := filepath.Join(b.T().TempDir(), "baseline.json")
+
+	empty, err := Load(path)
+	b.NoError(err, "should start empty without a file")
+	_, ok := empty.Series(denver)
+	b.False(ok)
+
+	b.NoError(b.baseline.Save(path))
+	loaded, err := Load(path)
+	b.NoError(err)
+
+	saved, _ := b.baseline.Series(denver)
+	restored, _ := loaded.Series(denver)
+	b.Equal(saved, restored, "should round trip every series")
+}
+
+func (b *BaselineTest) TestSink() {
+	var alerted []Score
+	sink := b.baseline.Sink(func(check schedule.Check, id gowup.JobID, anomalies []Score) {
+		alerted = append(alerted, anomalies...)
+	})
+
+	b.NoError(sink.Send(schedule.Check{Name: "google"}, "534419e98c3dcffa6170aeae", pinged(map[string]float64{"denver": 10, "riga": 40})))
+	b.Empty(alerted, "should not alert when everything's normal")
+
+	b.NoError(sink.Send(schedule.Check{Name: "google"}, "534419e98c3dcffa6170aeae", pinged(map[string]float64{"denver": 30, "riga": 40})))
+	b.Equal(1, len(alerted))
+	b.Equal("denver", alerted[0].Location)
+}
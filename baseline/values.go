@@ -0,0 +1,67 @@
+package baseline
+
+import (
+	"github.com/ellotheth/gowup"
+)
+
+// Values pulls one number per location and test out of a finished job: the
+// average round trip for ping, the total time for http, the round trip to
+// the last hop for trace, and the download speed in Mbps for fast. Pings
+// that got nothing back and traces that ended in a timeout have no number,
+// and neither do dig tests. The target is the job's URL, or its IP if
+// there's no URL.
+func Values(job *gowup.Job) (map[Key]float64, error) {
+	target := job.Summary.Ip
+	if job.Summary.Url.URL != nil {
+		target = job.Summary.Url.String()
+	}
+
+	values := map[Key]float64{}
+	key := func(city, test string) Key {
+		return Key{Target: target, Location: city, Test: test}
+	}
+
+	pings, err := job.PingResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, ping := range pings {
+		if ping.Loss < 100 {
+			values[key(city, "ping")] = ping.Avg
+		}
+	}
+
+	https, err := job.HttpResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, http := range https {
+		if http.Final() != nil {
+			values[key(city, "http")] = http.Time()
+		}
+	}
+
+	traces, err := job.TraceResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, trace := range traces {
+		if len(trace) > 0 && trace[len(trace)-1].Responded() {
+			values[key(city, "trace")] = trace[len(trace)-1].Avg()
+		}
+	}
+
+	fasts, err := job.FastResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, fast := range fasts {
+		mbps, err := fast.Mbps()
+		if err != nil {
+			return nil, err
+		}
+		values[key(city, "fast")] = mbps
+	}
+
+	return values, nil
+}
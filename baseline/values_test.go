@@ -0,0 +1,50 @@
+package baseline
+
+import (
+	"github.com/ellotheth/gowup"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ValuesTest struct {
+	suite.Suite
+}
+
+func TestValues(t *testing.T) {
+	suite.Run(t, new(ValuesTest))
+}
+
+func (v *ValuesTest) TestValues() {
+	job := &gowup.Job{Summary: gowup.JobSummary{Ip: "8.8.8.8"}, Details: gowup.JobDetails{Done: gowup.JobDetail{
+		"denver": {
+			"ping":  map[string]interface{}{"avg": 12.5, "loss": 0},
+			"http":  []interface{}{map[string]interface{}{"status": 301, "time": 20}, map[string]interface{}{"status": 200, "time": 60}},
+			"trace": []interface{}{map[string]interface{}{"ip": "10.0.0.1", "rtt": []float64{1}}, map[string]interface{}{"ip": "8.8.8.8", "rtt": []float64{20, 22}}},
+			"fast":  map[string]interface{}{"speed": 1, "unit": "Gbps"},
+			"dig":   map[string]interface{}{"answers": []interface{}{}},
+		},
+		"riga": {
+			"ping":  map[string]interface{}{"loss": 100},
+			"trace": []interface{}{map[string]interface{}{"ip": "10.0.0.1", "rtt": []float64{1}}, map[string]interface{}{"ip": "*", "timeouts": 3}},
+		},
+	}}}
+
+	values, err := Values(job)
+	v.NoError(err)
+	v.Equal(map[Key]float64{
+		{Target: "8.8.8.8", Location: "denver", Test: "ping"}:  12.5,
+		{Target: "8.8.8.8", Location: "denver", Test: "http"}:  80,
+		{Target: "8.8.8.8", Location: "denver", Test: "trace"}: 21,
+		{Target: "8.8.8.8", Location: "denver", Test: "fast"}:  1000,
+	}, values, "should skip lost pings, timed out traces and digs")
+}
+
+func (v *ValuesTest) TestBadResult() {
+	job := &gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{"denver": {"ping": "nonsense"}}}}
+	_, err := Values(job)
+	v.Error(err)
+
+	job = &gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{"denver": {"fast": map[string]interface{}{"speed": 1, "unit": "parsecs"}}}}}
+	_, err = Values(job)
+	v.Error(err)
+}
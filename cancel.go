@@ -0,0 +1,85 @@
+package gowup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrJobNotFound means the API doesn't know the job, or it's expired.
+	ErrJobNotFound = &Error{msg: "Job not found"}
+	// ErrJobComplete means the job already finished, so there's nothing to
+	// cancel.
+	ErrJobComplete = &Error{msg: "Job already complete"}
+)
+
+// CancelJob stops a job that's still running. Results that are already in
+// stay in.
+func (api WIU) CancelJob(id JobID) (err error) {
+	ctx, op := api.startOperation(context.Background(), "CancelJob")
+	op.set("wup.job_id", id.String())
+	defer func() { op.finish(err) }()
+
+	if err := api.checkJobID(id); err != nil {
+		return err
+	}
+
+	response, err := api.post(ctx, "jobs/"+id.String()+"/cancel", nil)
+	if err != nil {
+		return err
+	}
+
+	return api.checkJobResponse(response)
+}
+
+// DeleteJob removes a job and its results, finished or not.
+func (api WIU) DeleteJob(id JobID) (err error) {
+	ctx, op := api.startOperation(context.Background(), "DeleteJob")
+	op.set("wup.job_id", id.String())
+	defer func() { op.finish(err) }()
+
+	if err := api.checkJobID(id); err != nil {
+		return err
+	}
+
+	response, err := api.delete(ctx, "jobs/"+id.String())
+	if err != nil {
+		return err
+	}
+
+	return api.checkJobResponse(response)
+}
+
+// checkJobResponse turns the status of a cancel or delete into an error.
+func (api WIU) checkJobResponse(response *http.Response) error {
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return ErrJobNotFound
+	case response.StatusCode == http.StatusConflict:
+		return ErrJobComplete
+	case response.StatusCode >= 400:
+		return &Error{msg: "Request failed: " + response.Status}
+	}
+
+	return nil
+}
+
+// WaitOrCancel is Wait, except that if ctx is done before the job finishes,
+// the job gets cancelled too so it stops using up your account. The error
+// still wraps the context's, with a note if the cancel failed.
+func (api WIU) WaitOrCancel(ctx context.Context, id JobID, interval time.Duration) (*Job, error) {
+	job, err := api.Wait(ctx, id, interval)
+	if err == nil || ctx.Err() == nil {
+		return job, err
+	}
+
+	if cancelErr := api.CancelJob(id); cancelErr != nil && cancelErr != ErrJobComplete && cancelErr != ErrJobNotFound {
+		return job, fmt.Errorf("%w (and cancelling the job failed: %v)", err, cancelErr)
+	}
+
+	return job, err
+}
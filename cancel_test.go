@@ -0,0 +1,115 @@
+package gowup
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type CancelTest struct {
+	suite.Suite
+	api      WIU
+	mu       sync.Mutex
+	requests []string
+	status   int
+}
+
+func TestCancel(t *testing.T) {
+	suite.Run(t, new(CancelTest))
+}
+
+func (c *CancelTest) SetupTest() {
+	c.api = WIU{Client: "herp", Token: "derp"}
+	c.requests, c.status = nil, 200
+}
+
+// server records every request and answers job polls with an unfinished job
+func (c *CancelTest) server() *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		c.requests = append(c.requests, r.Method+" "+r.URL.Path)
+		c.mu.Unlock()
+
+		if r.Method == "GET" {
+			w.Write([]byte(`{"response": {"complete": [], "error": [], "in_progress": {"denver": {"ping": {}}}}}`))
+			return
+		}
+		w.WriteHeader(c.status)
+		w.Write([]byte(`{}`))
+	}))
+	apiEntryPoint = server.URL
+	return server
+}
+
+func (c *CancelTest) TestCancelJob() {
+	defer c.server().Close()
+
+	c.NoError(c.api.CancelJob("534419e98c3dcffa6170aeae"))
+	c.Equal([]string{"POST /jobs/534419e98c3dcffa6170aeae/cancel"}, c.requests)
+}
+
+func (c *CancelTest) TestDeleteJob() {
+	defer c.server().Close()
+
+	c.NoError(c.api.DeleteJob("534419e98c3dcffa6170aeae"))
+	c.Equal([]string{"DELETE /jobs/534419e98c3dcffa6170aeae"}, c.requests)
+}
+
+func (c *CancelTest) TestTypedErrors() {
+	defer c.server().Close()
+
+	c.status = 404
+	c.Equal(ErrJobNotFound, c.api.CancelJob("534419e98c3dcffa6170aeae"), "should recognize unknown jobs")
+
+	c.status = 409
+	c.Equal(ErrJobComplete, c.api.CancelJob("534419e98c3dcffa6170aeae"), "should recognize finished jobs")
+
+	c.status = 500
+	err := c.api.DeleteJob("534419e98c3dcffa6170aeae")
+	c.Error(err)
+	c.Contains(err.Error(), "500")
+
+	c.Error(c.api.CancelJob("nope"), "should validate the job id")
+}
+
+func (c *CancelTest) TestWaitOrCancel() {
+	defer c.server().Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.api.WaitOrCancel(ctx, "534419e98c3dcffa6170aeae", time.Millisecond)
+	c.ErrorIs(err, context.DeadlineExceeded, "should still return the context error")
+	c.Equal("POST /jobs/534419e98c3dcffa6170aeae/cancel", c.requests[len(c.requests)-1], "should cancel the job")
+}
+
+func (c *CancelTest) TestWaitOrCancelFailure() {
+	defer c.server().Close()
+	c.status = 500
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.api.WaitOrCancel(ctx, "534419e98c3dcffa6170aeae", time.Millisecond)
+	c.ErrorIs(err, context.DeadlineExceeded)
+	c.True(strings.Contains(err.Error(), "cancelling the job failed"), "should mention the failed cancel")
+}
+
+func (c *CancelTest) TestWaitOrCancelFinished() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.requests = append(c.requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"response": {"complete": {"denver": {"ping": {}}}, "error": [], "in_progress": []}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	job, err := c.api.WaitOrCancel(context.Background(), "534419e98c3dcffa6170aeae", time.Millisecond)
+	c.NoError(err)
+	c.True(job.Finished())
+	c.Equal([]string{"GET /jobs/534419e98c3dcffa6170aeae"}, c.requests, "should not cancel finished jobs")
+}
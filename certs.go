@@ -0,0 +1,67 @@
+package gowup
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// CertStatus is the certificate one location was served on its final
+// response. Left is how long until it expires, negative once it has.
+type CertStatus struct {
+	Location string
+	TlsInfo
+	Left time.Duration
+}
+
+// Days is Left in whole days, rounded down.
+func (c CertStatus) Days() int {
+	return int(math.Floor(c.Left.Hours() / 24))
+}
+
+// Certificates lists the certificate from every location with a finished
+// HTTPS test, soonest expiry first. Locations served different certificates
+// (by different CDN edges, say) each show up with their own.
+func (j Job) Certificates() ([]CertStatus, error) {
+	results, err := j.HttpResults()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	certs := []CertStatus{}
+	for city, result := range results {
+		tls := result.Tls()
+		if tls == nil {
+			continue
+		}
+		certs = append(certs, CertStatus{Location: city, TlsInfo: *tls, Left: tls.Expiry.Sub(now)})
+	}
+
+	sort.Slice(certs, func(a, b int) bool {
+		if !certs[a].Expiry.Equal(certs[b].Expiry.Time) {
+			return certs[a].Expiry.Before(certs[b].Expiry.Time)
+		}
+		return certs[a].Location < certs[b].Location
+	})
+
+	return certs, nil
+}
+
+// ExpiringCerts is Certificates cut down to the ones that expire within
+// window, or already have.
+func (j Job) ExpiringCerts(window time.Duration) ([]CertStatus, error) {
+	certs, err := j.Certificates()
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := []CertStatus{}
+	for _, cert := range certs {
+		if cert.Left <= window {
+			expiring = append(expiring, cert)
+		}
+	}
+
+	return expiring, nil
+}
@@ -0,0 +1,70 @@
+package gowup
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type CertsTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestCerts(t *testing.T) {
+	suite.Run(t, new(CertsTest))
+}
+
+func (c *CertsTest) SetupTest() {
+	served := func(issuer string, left time.Duration) interface{} {
+		return []interface{}{map[string]interface{}{
+			"status": 200,
+			"tls":    map[string]interface{}{"subject": "CN=example.com", "issuer": issuer, "expiry": time.Now().Add(left).Unix()},
+		}}
+	}
+
+	c.job = Job{Details: JobDetails{Done: JobDetail{
+		"denver": {"http": served("CN=Old CA", 10*24*time.Hour+time.Hour)},
+		"riga":   {"http": served("CN=New CA", 90*24*time.Hour+time.Hour)},
+		"tokyo":  {"http": served("CN=Old CA", -36*time.Hour)},
+		"sydney": {"http": []interface{}{map[string]interface{}{"status": 200}}},
+	}}}
+}
+
+func (c *CertsTest) TestCertificates() {
+	certs, err := c.job.Certificates()
+	c.NoError(err)
+	c.Equal(3, len(certs), "should skip locations without certificates")
+
+	c.Equal("tokyo", certs[0].Location, "should sort by expiry")
+	c.Equal(-2, certs[0].Days(), "should count expired certificates in negative days")
+	c.Equal("denver", certs[1].Location)
+	c.Equal(10, certs[1].Days())
+	c.Equal("CN=Old CA", certs[1].Issuer)
+	c.Equal("riga", certs[2].Location)
+}
+
+func (c *CertsTest) TestExpiringCerts() {
+	certs, err := c.job.ExpiringCerts(30 * 24 * time.Hour)
+	c.NoError(err)
+	c.Equal(2, len(certs), "should include expired certificates")
+	c.Equal("denver", certs[1].Location)
+
+	certs, _ = c.job.ExpiringCerts(0)
+	c.Equal(1, len(certs))
+}
+
+func (c *CertsTest) TestAssertion() {
+	result, err := Assertion{Test: "http", Metric: "cert_days", Op: ">=", Value: 30}.Evaluate(c.job, nil)
+	c.NoError(err)
+	c.False(result.Passed)
+	c.True(result.Locations["riga"].Passed)
+	c.Equal("10", result.Locations["denver"].Actual)
+	c.Equal("no certificate", result.Locations["sydney"].Reason)
+}
+
+func (c *CertsTest) TestBadResult() {
+	c.job.Details.Done["denver"]["http"] = "nonsense"
+	_, err := c.job.Certificates()
+	c.Error(err)
+}
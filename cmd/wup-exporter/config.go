@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"time"
+)
+
+// config lists the targets to check. Locations at the top level apply to
+// every target that doesn't list its own.
+//
+//	interval: 5m
+//	locations: [denver, riga]
+//	targets:
+//	  - url: https://example.com
+//	    tests: [ping, http, dig]
+type config struct {
+	Interval  time.Duration `yaml:"interval"`
+	Locations []string      `yaml:"locations"`
+	Targets   []target      `yaml:"targets"`
+}
+
+type target struct {
+	Url       string   `yaml:"url"`
+	Tests     []string `yaml:"tests"`
+	Locations []string `yaml:"locations"`
+}
+
+func loadConfig(path string) (*config, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseConfig(raw)
+}
+
+func parseConfig(raw []byte) (*config, error) {
+	c := &config{Interval: 5 * time.Minute}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+
+	if len(c.Targets) == 0 {
+		return nil, errors.New("no targets configured")
+	}
+	if c.Interval < time.Minute {
+		return nil, errors.New("interval must be at least a minute")
+	}
+
+	for i := range c.Targets {
+		t := &c.Targets[i]
+		if t.Url == "" {
+			return nil, errors.New("every target needs a url")
+		}
+		if len(t.Tests) == 0 {
+			t.Tests = []string{"ping"}
+		}
+		if len(t.Locations) == 0 {
+			t.Locations = c.Locations
+		}
+		if len(t.Locations) == 0 {
+			return nil, errors.New("no locations configured for " + t.Url)
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"github.com/ellotheth/gowup"
+	"log"
+	"time"
+)
+
+// client is the part of gowup.WIU the exporter needs, so tests can fake it.
+type client interface {
+	Submit(req *gowup.JobRequest) (gowup.JobID, error)
+	Wait(ctx context.Context, id gowup.JobID, interval time.Duration) (*gowup.Job, error)
+}
+
+type exporter struct {
+	api     client
+	config  *config
+	metrics *registry
+	poll    time.Duration
+}
+
+func newExporter(api client, c *config) *exporter {
+	return &exporter{api: api, config: c, metrics: newRegistry(), poll: 10 * time.Second}
+}
+
+// Start checks every target once per interval until ctx is done.
+func (e *exporter) Start(ctx context.Context) {
+	for _, t := range e.config.Targets {
+		go func(t target) {
+			ticker := time.NewTicker(e.config.Interval)
+			defer ticker.Stop()
+
+			for {
+				e.run(ctx, t)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(t)
+	}
+
+	<-ctx.Done()
+}
+
+// run submits one job for the target, waits for it, and records the results.
+// A job gets until the next interval to finish.
+func (e *exporter) run(ctx context.Context, t target) {
+	id, err := e.api.Submit(&gowup.JobRequest{Url: t.Url, Tests: t.Tests, Locations: t.Locations})
+	if err != nil {
+		e.fail(t, "submit", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.config.Interval)
+	defer cancel()
+
+	job, err := e.api.Wait(ctx, id, e.poll)
+	if err != nil {
+		e.fail(t, "wait", err)
+		return
+	}
+
+	e.record(t, job)
+	e.metrics.Set("wup_last_success_timestamp_seconds", map[string]string{"target": t.Url}, float64(time.Now().Unix()))
+}
+
+func (e *exporter) fail(t target, stage string, err error) {
+	log.Printf("%s: %s failed: %s", t.Url, stage, err)
+	e.metrics.Add("wup_job_failures_total", map[string]string{"target": t.Url, "stage": stage}, 1)
+}
+
+// locationGauges are the per-location results. Each job replaces the
+// target's whole set, so a location that stops reporting or errors out
+// drops out instead of keeping its last value.
+var locationGauges = []string{"wup_ping_rtt_ms", "wup_ping_loss_percent", "wup_http_status", "wup_http_time_ms", "wup_dns_answers"}
+
+func (e *exporter) record(t target, job *gowup.Job) {
+	labels := func(city string) map[string]string {
+		return map[string]string{"location": city, "target": t.Url}
+	}
+
+	var fresh []metric
+	set := func(name, city string, value float64) {
+		fresh = append(fresh, metric{name: name, labels: labels(city), value: value})
+	}
+
+	if pings, err := job.PingResults(); err != nil {
+		log.Printf("%s: %s", t.Url, err)
+	} else {
+		for city, ping := range pings {
+			// no replies means no round trip time, not a zero one
+			if ping.Loss < 100 {
+				set("wup_ping_rtt_ms", city, ping.Avg)
+			}
+			set("wup_ping_loss_percent", city, ping.Loss)
+		}
+	}
+
+	if https, err := job.HttpResults(); err != nil {
+		log.Printf("%s: %s", t.Url, err)
+	} else {
+		for city, http := range https {
+			if final := http.Final(); final != nil {
+				set("wup_http_status", city, float64(final.Status))
+			}
+			set("wup_http_time_ms", city, http.Time())
+		}
+	}
+
+	if digs, err := job.DigResults(); err != nil {
+		log.Printf("%s: %s", t.Url, err)
+	} else {
+		for city, dig := range digs {
+			set("wup_dns_answers", city, float64(len(dig.Answers)))
+		}
+	}
+
+	e.metrics.Replace(locationGauges, map[string]string{"target": t.Url}, fresh)
+
+	for city, tests := range job.Details.Error {
+		for test := range tests {
+			l := labels(city)
+			l["test"] = test
+			e.metrics.Add("wup_test_errors_total", l, 1)
+		}
+	}
+}
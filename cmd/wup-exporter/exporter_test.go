@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/ellotheth/gowup"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeApi hands back a canned job for every submission.
+type fakeApi struct {
+	submitted []*gowup.JobRequest
+	job       *gowup.Job
+	err       error
+}
+
+func (f *fakeApi) Submit(req *gowup.JobRequest) (gowup.JobID, error) {
+	f.submitted = append(f.submitted, req)
+	if f.err != nil {
+		return "", f.err
+	}
+	return "534419e98c3dcffa6170aeae", nil
+}
+
+func (f *fakeApi) Wait(ctx context.Context, id gowup.JobID, interval time.Duration) (*gowup.Job, error) {
+	return f.job, nil
+}
+
+type ExporterTest struct {
+	suite.Suite
+	api      *fakeApi
+	exporter *exporter
+	target   target
+}
+
+func TestExporter(t *testing.T) {
+	suite.Run(t, new(ExporterTest))
+}
+
+func (e *ExporterTest) SetupTest() {
+	e.api = &fakeApi{job: &gowup.Job{Details: gowup.JobDetails{
+		Done: gowup.JobDetail{
+			"denver": {
+				"ping": map[string]interface{}{"avg": 12.5, "loss": 0},
+				"http": []interface{}{map[string]interface{}{"status": 200, "time": 80}},
+				"dig":  map[string]interface{}{"answers": []interface{}{map[string]interface{}{"data": "1.2.3.4"}}},
+			},
+		},
+		Error: gowup.JobDetail{"riga": {"ping": nil}},
+	}}}
+
+	e.target = target{Url: "https://example.com", Tests: []string{"ping", "http", "dig"}, Locations: []string{"denver", "riga"}}
+	e.exporter = newExporter(e.api, &config{Interval: time.Minute, Targets: []target{e.target}})
+}
+
+func (e *ExporterTest) scrape() string {
+	server := httptest.NewServer(e.exporter.metrics)
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	e.Require().NoError(err)
+	defer response.Body.Close()
+
+	body, _ := ioutil.ReadAll(response.Body)
+	return string(body)
+}
+
+func (e *ExporterTest) TestRun() {
+	e.exporter.run(context.Background(), e.target)
+
+	e.Equal([]string{"denver", "riga"}, e.api.submitted[0].Locations, "should submit the target's locations")
+
+	metrics := e.scrape()
+	e.Contains(metrics, "# TYPE wup_ping_rtt_ms gauge\n")
+	e.Contains(metrics, `wup_ping_rtt_ms{location="denver",target="https://example.com"} 12.5`)
+	e.Contains(metrics, `wup_http_status{location="denver",target="https://example.com"} 200`)
+	e.Contains(metrics, `wup_dns_answers{location="denver",target="https://example.com"} 1`)
+	e.Contains(metrics, `wup_test_errors_total{location="riga",target="https://example.com",test="ping"} 1`)
+	e.Contains(metrics, `wup_last_success_timestamp_seconds{target="https://example.com"}`)
+}
+
+func (e *ExporterTest) TestStaleLocations() {
+	e.exporter.metrics.Set("wup_ping_rtt_ms", map[string]string{"location": "denver", "target": "https://example.org"}, 20)
+	e.exporter.run(context.Background(), e.target)
+
+	e.api.job = &gowup.Job{Details: gowup.JobDetails{
+		Done:  gowup.JobDetail{"riga": {"ping": map[string]interface{}{"avg": 30, "loss": 0}}},
+		Error: gowup.JobDetail{"denver": {"ping": nil, "http": nil, "dig": nil}},
+	}}
+	e.exporter.run(context.Background(), e.target)
+
+	metrics := e.scrape()
+	e.NotContains(metrics, `wup_ping_rtt_ms{location="denver",target="https://example.com"}`, "should drop locations that stopped reporting")
+	e.NotContains(metrics, `wup_http_status{location="denver",target="https://example.com"}`)
+	e.Contains(metrics, `wup_ping_rtt_ms{location="riga",target="https://example.com"} 30`)
+	e.Contains(metrics, `wup_ping_rtt_ms{location="denver",target="https://example.org"} 20`, "should leave other targets alone")
+}
+
+func (e *ExporterTest) TestNoReplies() {
+	e.api.job = &gowup.Job{Details: gowup.JobDetails{
+		Done: gowup.JobDetail{"sydney": {"ping": map[string]interface{}{"transmitted": 3, "received": 0, "loss": 100}}},
+	}}
+	e.exporter.run(context.Background(), e.target)
+
+	metrics := e.scrape()
+	e.NotContains(metrics, `wup_ping_rtt_ms{location="sydney"`, "should not export a round trip time without replies")
+	e.Contains(metrics, `wup_ping_loss_percent{location="sydney",target="https://example.com"} 100`)
+}
+
+func (e *ExporterTest) TestSubmitFailure() {
+	e.api.err = errors.New("nope")
+
+	e.exporter.run(context.Background(), e.target)
+	e.exporter.run(context.Background(), e.target)
+
+	e.Contains(e.scrape(), `wup_job_failures_total{stage="submit",target="https://example.com"} 2`, "should count failed jobs")
+}
+
+func (e *ExporterTest) TestLabelEscaping() {
+	e.Equal(`{a="say \"hi\"",b="x\\y"}`, formatLabels(map[string]string{"b": `x\y`, "a": `say "hi"`}))
+}
+
+func (e *ExporterTest) TestParseConfig() {
+	c, err := parseConfig([]byte(`
+interval: 10m
+locations: [denver]
+targets:
+  - url: https://example.com
+  - url: https://example.org
+    tests: [http]
+    locations: [riga]
+`))
+	e.NoError(err, "should not return an error")
+	e.Equal(10*time.Minute, c.Interval)
+	e.Equal([]string{"ping"}, c.Targets[0].Tests, "should default to ping")
+	e.Equal([]string{"denver"}, c.Targets[0].Locations, "should inherit locations")
+	e.Equal([]string{"riga"}, c.Targets[1].Locations, "should keep target locations")
+}
+
+func (e *ExporterTest) TestBadConfig() {
+	_, err := parseConfig([]byte(`targets: []`))
+	e.Error(err, "should need targets")
+
+	_, err = parseConfig([]byte(`{interval: 1s, locations: [denver], targets: [{url: x}]}`))
+	e.Error(err, "should reject short intervals")
+
+	_, err = parseConfig([]byte(`{targets: [{url: x}]}`))
+	e.Error(err, "should need locations")
+}
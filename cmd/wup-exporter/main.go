@@ -0,0 +1,37 @@
+// wup-exporter runs Where's it Up checks on a schedule and exposes the
+// results as Prometheus metrics.
+//
+// Credentials come from the profile named by -profile (see
+// gowup.FromProfile), or the WUP_CLIENT and WUP_TOKEN environment variables.
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/ellotheth/gowup"
+	"log"
+	"net/http"
+)
+
+func main() {
+	path := flag.String("config", "wup-exporter.yaml", "path to the config file")
+	listen := flag.String("listen", ":9150", "address to serve /metrics on")
+	profile := flag.String("profile", "", "credentials profile to use")
+	flag.Parse()
+
+	config, err := loadConfig(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	api, err := gowup.FromProfile(*profile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exporter := newExporter(api, config)
+	go exporter.Start(context.Background())
+
+	http.Handle("/metrics", exporter.metrics)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// help text and type for every metric the exporter knows about.
+var families = map[string][2]string{
+	"wup_ping_rtt_ms":                    {"Average ping round trip time.", "gauge"},
+	"wup_ping_loss_percent":              {"Ping packet loss.", "gauge"},
+	"wup_http_status":                    {"Final HTTP status code.", "gauge"},
+	"wup_http_time_ms":                   {"Time for the whole HTTP redirect chain.", "gauge"},
+	"wup_dns_answers":                    {"Number of answers to the dig query.", "gauge"},
+	"wup_test_errors_total":              {"Tests that came back with an error.", "counter"},
+	"wup_job_failures_total":             {"Jobs that couldn't be submitted or finished.", "counter"},
+	"wup_last_success_timestamp_seconds": {"When the last job for the target finished.", "gauge"},
+}
+
+// registry holds the current value of every series and renders them in the
+// Prometheus text format. The client library is a lot of dependency for a
+// handful of gauges.
+type registry struct {
+	sync.Mutex
+	series map[string]map[string]sample
+}
+
+// sample is one series' labels and current value.
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// metric is a value for one series in a family.
+type metric struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func newRegistry() *registry {
+	return &registry{series: map[string]map[string]sample{}}
+}
+
+func (r *registry) Set(name string, labels map[string]string, value float64) {
+	r.Lock()
+	defer r.Unlock()
+	r.family(name)[formatLabels(labels)] = sample{labels: labels, value: value}
+}
+
+func (r *registry) Add(name string, labels map[string]string, value float64) {
+	r.Lock()
+	defer r.Unlock()
+	key := formatLabels(labels)
+	r.family(name)[key] = sample{labels: labels, value: r.family(name)[key].value + value}
+}
+
+// Replace drops every series in the named families whose labels include all
+// of match, and sets metrics in their place. It's done in one go, so a scrape
+// never sees half of it.
+func (r *registry) Replace(names []string, match map[string]string, metrics []metric) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, name := range names {
+		for key, s := range r.series[name] {
+			if matches(s.labels, match) {
+				delete(r.series[name], key)
+			}
+		}
+	}
+
+	for _, m := range metrics {
+		r.family(m.name)[formatLabels(m.labels)] = sample{labels: m.labels, value: m.value}
+	}
+}
+
+func (r *registry) family(name string) map[string]sample {
+	if _, ok := r.series[name]; !ok {
+		r.series[name] = map[string]sample{}
+	}
+	return r.series[name]
+}
+
+func matches(labels, match map[string]string) bool {
+	for k, v := range match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, r.String())
+}
+
+func (r *registry) String() string {
+	r.Lock()
+	defer r.Unlock()
+
+	names := make([]string, 0, len(r.series))
+	for name := range r.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		meta := families[name]
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", name, meta[0], name, meta[1])
+
+		labels := make([]string, 0, len(r.series[name]))
+		for l := range r.series[name] {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+
+		for _, l := range labels {
+			fmt.Fprintf(&out, "%s%s %g\n", name, l, r.series[name][l].value)
+		}
+	}
+
+	return out.String()
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[k])
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, value))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ellotheth/gowup"
+	"os"
+)
+
+// cancel cancels or deletes a job, depending on the command. It returns the
+// exit code.
+func cancel(api gowup.WIU, command string, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: wup %s <job id>\n", command)
+		return 2
+	}
+
+	id, err := gowup.ParseJobID(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if command == "delete" {
+		err = api.DeleteJob(id)
+	} else {
+		err = api.CancelJob(id)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/ellotheth/gowup"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Nagios plugin exit codes.
+const (
+	stateOk = iota
+	stateWarning
+	stateCritical
+	stateUnknown
+)
+
+var stateNames = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// check submits a job, waits for it and compares the worst value of metric
+// across locations against the thresholds. It returns the exit code.
+func check(api gowup.WIU, args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(out)
+	url := flags.String("url", "", "target URL")
+	test := flags.String("test", "ping", "test to run: ping or http")
+	locations := flags.String("locations", "", "comma separated source locations")
+	metric := flags.String("metric", "latency", "metric to check: latency, loss or status")
+	warning := flags.Float64("w", math.Inf(1), "warning threshold")
+	critical := flags.Float64("c", math.Inf(1), "critical threshold")
+	timeout := flags.Duration("timeout", 2*time.Minute, "how long to wait for results")
+	interval := flags.Duration("interval", 5*time.Second, "how often to poll for results")
+
+	if err := flags.Parse(args); err != nil {
+		return stateUnknown
+	}
+	if *url == "" || *locations == "" {
+		fmt.Fprintln(out, "WUP UNKNOWN - -url and -locations are required")
+		return stateUnknown
+	}
+
+	id, err := api.Submit(&gowup.JobRequest{
+		Url:       *url,
+		Tests:     []string{*test},
+		Locations: strings.Split(*locations, ","),
+	})
+	if err != nil {
+		return unknown(out, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	job, err := api.WaitOrCancel(ctx, id, *interval)
+	if err != nil {
+		return unknown(out, err)
+	}
+
+	state, message := evaluate(*job, *test, *metric, *warning, *critical)
+	fmt.Fprintln(out, message)
+
+	return state
+}
+
+// unknown reports something that stopped the check from getting results,
+// like missing credentials. Nagios would read anything else as the target
+// being down.
+func unknown(out io.Writer, err error) int {
+	fmt.Fprintln(out, "WUP UNKNOWN - "+err.Error())
+	return stateUnknown
+}
+
+// evaluate builds the status line for a finished job. Values above the
+// thresholds alert; so does any location that errored out.
+func evaluate(job gowup.Job, test, metric string, warning, critical float64) (int, string) {
+	values, unit, err := metricValues(job, test, metric)
+	if err != nil {
+		return stateUnknown, "WUP UNKNOWN - " + err.Error()
+	}
+
+	var failed []string
+	for city, tests := range job.Details.Error {
+		if _, ok := tests[test]; ok {
+			failed = append(failed, city)
+		}
+	}
+	sort.Strings(failed)
+
+	if len(values) == 0 && len(failed) == 0 {
+		return stateUnknown, "WUP UNKNOWN - no " + test + " results"
+	}
+
+	cities := make([]string, 0, len(values))
+	for city := range values {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+
+	worst, worstCity := math.Inf(-1), ""
+	perfdata := make([]string, 0, len(cities))
+	for _, city := range cities {
+		if values[city] > worst {
+			worst, worstCity = values[city], city
+		}
+		perfdata = append(perfdata, fmt.Sprintf("'%s_%s'=%s%s;%s;%s",
+			city, metric, number(values[city]), unit, threshold(warning), threshold(critical)))
+	}
+
+	state := stateOk
+	if worst > warning {
+		state = stateWarning
+	}
+	if worst > critical || len(failed) > 0 {
+		state = stateCritical
+	}
+
+	summary := fmt.Sprintf("%s %s", test, metric)
+	if worstCity != "" {
+		summary += fmt.Sprintf(" %s%s (worst: %s)", number(worst), unit, worstCity)
+	}
+	if len(failed) > 0 {
+		summary += ", failed from " + strings.Join(failed, ", ")
+	}
+
+	return state, fmt.Sprintf("WUP %s - %s | %s", stateNames[state], summary, strings.Join(perfdata, " "))
+}
+
+// metricValues gets metric for every location that finished test, along with
+// its perfdata unit.
+func metricValues(job gowup.Job, test, metric string) (map[string]float64, string, error) {
+	values := map[string]float64{}
+
+	switch {
+	case metric == "latency":
+		stats, err := job.LocationStats(test)
+		if err != nil {
+			return nil, "", err
+		}
+		for city, s := range stats {
+			values[city] = s.Avg
+		}
+		return values, "ms", nil
+	case metric == "loss" && test == "ping":
+		results, err := job.PingResults()
+		if err != nil {
+			return nil, "", err
+		}
+		for city, result := range results {
+			values[city] = result.Loss
+		}
+		return values, "%", nil
+	case metric == "status" && test == "http":
+		results, err := job.HttpResults()
+		if err != nil {
+			return nil, "", err
+		}
+		for city, result := range results {
+			if final := result.Final(); final != nil {
+				values[city] = float64(final.Status)
+			}
+		}
+		return values, "", nil
+	}
+
+	return nil, "", fmt.Errorf("can't check %s on %s tests", metric, test)
+}
+
+func number(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
+
+func threshold(value float64) string {
+	if math.IsInf(value, 1) {
+		return ""
+	}
+	return number(value)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ellotheth/gowup"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"math"
+	"testing"
+)
+
+type CheckTest struct {
+	suite.Suite
+	job gowup.Job
+}
+
+func TestCheck(t *testing.T) {
+	suite.Run(t, new(CheckTest))
+}
+
+func (c *CheckTest) SetupTest() {
+	c.job = gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{
+		"denver": {
+			"ping": map[string]interface{}{"received": 4, "loss": 0, "avg": 45.5},
+			"http": []interface{}{map[string]interface{}{"status": 200, "time": 90}},
+		},
+		"riga": {
+			"ping": map[string]interface{}{"received": 3, "loss": 25, "avg": 120},
+			"http": []interface{}{map[string]interface{}{"status": 503, "time": 30}},
+		},
+	}}}
+}
+
+func (c *CheckTest) TestOk() {
+	state, message := evaluate(c.job, "ping", "latency", 200, 300)
+	c.Equal(stateOk, state)
+	c.Equal("WUP OK - ping latency 120ms (worst: riga) | 'denver_latency'=45.5ms;200;300 'riga_latency'=120ms;200;300", message)
+}
+
+func (c *CheckTest) TestWarning() {
+	state, _ := evaluate(c.job, "ping", "loss", 10, 50)
+	c.Equal(stateWarning, state, "should warn over the warning threshold")
+}
+
+func (c *CheckTest) TestCritical() {
+	state, message := evaluate(c.job, "http", "status", math.Inf(1), 499)
+	c.Equal(stateCritical, state, "should go critical over the critical threshold")
+	c.Contains(message, "'riga_status'=503;;499", "should leave out unset thresholds")
+}
+
+func (c *CheckTest) TestErroredLocations() {
+	c.job.Details.Error = gowup.JobDetail{"sydney": {"ping": nil}}
+
+	state, message := evaluate(c.job, "ping", "latency", 200, 300)
+	c.Equal(stateCritical, state, "should go critical if any location failed")
+	c.Contains(message, "failed from sydney")
+}
+
+func (c *CheckTest) TestUnknown() {
+	state, _ := evaluate(c.job, "ping", "status", 1, 2)
+	c.Equal(stateUnknown, state, "should reject metrics the test doesn't have")
+
+	state, message := evaluate(gowup.Job{}, "ping", "latency", 1, 2)
+	c.Equal(stateUnknown, state, "should be unknown without results")
+	c.Equal("WUP UNKNOWN - no ping results", message)
+}
+
+func (c *CheckTest) TestUnknownError() {
+	var out bytes.Buffer
+	c.Equal(stateUnknown, unknown(&out, errors.New("no token in profile")), "should not page anyone over config problems")
+	c.Equal("WUP UNKNOWN - no token in profile\n", out.String())
+}
+
+func (c *CheckTest) TestMissingFlags() {
+	c.Equal(stateUnknown, check(gowup.WIU{}, []string{"-url", "https://google.com"}, ioutil.Discard))
+}
@@ -0,0 +1,48 @@
+// wup is a command line client for the Where's it Up API.
+//
+// Credentials come from the profile in WUP_PROFILE (see gowup.FromProfile),
+// or the WUP_CLIENT and WUP_TOKEN environment variables.
+package main
+
+import (
+	"fmt"
+	"github.com/ellotheth/gowup"
+	"os"
+)
+
+const usage = `usage: wup <command> [options]
+
+commands:
+    check    submit a job and report on it like a Nagios plugin
+    cancel   stop a running job
+    delete   delete a job and its results
+    report   write an HTML report for a job
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	api, err := gowup.FromProfile("")
+	if err != nil && os.Args[1] == "check" {
+		os.Exit(unknown(os.Stdout, err))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "check":
+		os.Exit(check(api, os.Args[2:], os.Stdout))
+	case "cancel", "delete":
+		os.Exit(cancel(api, os.Args[1], os.Args[2:]))
+	case "report":
+		os.Exit(report(api, os.Args[2:], os.Stdout))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ellotheth/gowup"
+	"io"
+	"os"
+)
+
+// report writes the HTML report for a job. It returns the exit code.
+func report(api gowup.WIU, args []string, out io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: wup report <job id> > report.html")
+		return 2
+	}
+
+	id, err := gowup.ParseJobID(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	job, err := api.Job(id)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := job.Report(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,120 @@
+package gowup
+
+import (
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Credentials is one named profile in the config file.
+type Credentials struct {
+	Client string `yaml:"client"`
+	Token  string `yaml:"token"`
+}
+
+// Config is the credentials file, ~/.config/wup/config.yaml unless WUP_CONFIG
+// says otherwise:
+//
+//	default: billing
+//	profiles:
+//	  billing:
+//	    client: <your WIU client ID>
+//	    token: <your WIU client token>
+//	  marketing:
+//	    client: ...
+//	    token: ...
+type Config struct {
+	Default  string                 `yaml:"default"`
+	Profiles map[string]Credentials `yaml:"profiles"`
+}
+
+// ConfigPath is where the credentials file lives.
+func ConfigPath() string {
+	if path := os.Getenv("WUP_CONFIG"); path != "" {
+		return path
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "wup", "config.yaml")
+}
+
+// LoadConfig reads the credentials file in path. A missing file is an empty
+// config. Anyone on the box can read a world-readable file, so that gets a
+// warning.
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{Profiles: map[string]Credentials{}}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0004 != 0 {
+		log.Printf("gowup: %s is world-readable; chmod 600 it to keep your token secret", path)
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, config); err != nil {
+		return nil, &Error{msg: "Invalid config file " + path + ": " + err.Error()}
+	}
+
+	return config, nil
+}
+
+// FromProfile builds a client from a profile in the credentials file. An
+// empty name means WUP_PROFILE, then the file's default, then "default".
+// WUP_CLIENT and WUP_TOKEN override whatever the profile says, so they work
+// without any file at all.
+func FromProfile(name string) (WIU, error) {
+	config, err := LoadConfig(ConfigPath())
+	if err != nil {
+		return WIU{}, err
+	}
+
+	return config.Profile(name)
+}
+
+// Profile builds a client from a profile in the config. See FromProfile.
+func (c *Config) Profile(name string) (WIU, error) {
+	if name == "" {
+		name = os.Getenv("WUP_PROFILE")
+	}
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		name = "default"
+	}
+
+	creds, ok := c.Profiles[name]
+	if client := os.Getenv("WUP_CLIENT"); client != "" {
+		creds.Client = client
+	}
+	if token := os.Getenv("WUP_TOKEN"); token != "" {
+		creds.Token = token
+	}
+
+	if creds.Client == "" || creds.Token == "" {
+		if !ok {
+			return WIU{}, &Error{msg: "No profile named '" + name + "'"}
+		}
+		return WIU{}, &Error{msg: "Profile '" + name + "' needs a client and a token"}
+	}
+
+	return WIU{Client: creds.Client, Token: creds.Token}, nil
+}
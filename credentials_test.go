@@ -0,0 +1,105 @@
+package gowup
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type CredentialsTest struct {
+	suite.Suite
+	path string
+}
+
+func TestCredentials(t *testing.T) {
+	suite.Run(t, new(CredentialsTest))
+}
+
+func (c *CredentialsTest) SetupTest() {
+	c.path = filepath.Join(c.T().TempDir(), "config.yaml")
+	ioutil.WriteFile(c.path, []byte(`
+default: billing
+profiles:
+  billing:
+    client: billing-client
+    token: billing-token
+  marketing:
+    client: marketing-client
+    token: marketing-token
+  broken:
+    client: nope
+`), 0600)
+
+	c.T().Setenv("WUP_CONFIG", c.path)
+	c.T().Setenv("WUP_PROFILE", "")
+	c.T().Setenv("WUP_CLIENT", "")
+	c.T().Setenv("WUP_TOKEN", "")
+}
+
+func (c *CredentialsTest) TestNamedProfile() {
+	api, err := FromProfile("marketing")
+	c.NoError(err, "should not return an error")
+	c.Equal(WIU{Client: "marketing-client", Token: "marketing-token"}, api)
+}
+
+func (c *CredentialsTest) TestDefaultProfile() {
+	api, err := FromProfile("")
+	c.NoError(err, "should not return an error")
+	c.Equal("billing-client", api.Client, "should use the file's default")
+
+	c.T().Setenv("WUP_PROFILE", "marketing")
+	api, _ = FromProfile("")
+	c.Equal("marketing-client", api.Client, "should prefer WUP_PROFILE")
+}
+
+func (c *CredentialsTest) TestEnvironmentOverrides() {
+	c.T().Setenv("WUP_TOKEN", "env-token")
+
+	api, err := FromProfile("billing")
+	c.NoError(err, "should not return an error")
+	c.Equal(WIU{Client: "billing-client", Token: "env-token"}, api, "should override the profile")
+}
+
+func (c *CredentialsTest) TestEnvironmentOnly() {
+	c.T().Setenv("WUP_CONFIG", filepath.Join(c.T().TempDir(), "missing.yaml"))
+	c.T().Setenv("WUP_CLIENT", "env-client")
+	c.T().Setenv("WUP_TOKEN", "env-token")
+
+	api, err := FromProfile("")
+	c.NoError(err, "should work without a config file")
+	c.Equal(WIU{Client: "env-client", Token: "env-token"}, api)
+}
+
+func (c *CredentialsTest) TestBadProfiles() {
+	_, err := FromProfile("sales")
+	c.Error(err, "should reject missing profiles")
+	c.Equal("No profile named 'sales'", err.Error())
+
+	_, err = FromProfile("broken")
+	c.Error(err, "should reject incomplete profiles")
+}
+
+func (c *CredentialsTest) TestWorldReadable() {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	_, err := LoadConfig(c.path)
+	c.NoError(err)
+	c.Empty(logged.String(), "should not warn about private files")
+
+	os.Chmod(c.path, 0644)
+	_, err = LoadConfig(c.path)
+	c.NoError(err)
+	c.Contains(logged.String(), "world-readable", "should warn about world-readable files")
+}
+
+func (c *CredentialsTest) TestConfigPath() {
+	c.T().Setenv("WUP_CONFIG", "")
+	c.T().Setenv("XDG_CONFIG_HOME", "/etc/xdg")
+	c.Equal("/etc/xdg/wup/config.yaml", ConfigPath())
+}
@@ -0,0 +1,178 @@
+package gowup
+
+import (
+	"sort"
+	"strings"
+)
+
+// EdgeReport maps out which CDN edge serves each location in a job: the
+// addresses the location's dig resolved for the target host, the headers
+// from its HTTP test that say which edge answered, and the locations grouped
+// by edge, so each PoP's catchment is easy to see.
+//
+// Where's it Up only reports one connected IP for the whole job (Ip).
+// Locations that resolved somewhere else are flagged, since they probably
+// didn't hit that address. Locations without a dig result aren't.
+type EdgeReport struct {
+	Target    string
+	Ip        string
+	Locations []EdgeLocation
+	Edges     []EdgeGroup
+}
+
+// EdgeLocation is what one location resolved and hit. Edge is the CDN and
+// PoP, e.g. "Cloudflare DEN", or the first resolved address if no header
+// gave the edge away. Headers has the headers the edge was worked out from.
+type EdgeLocation struct {
+	Location string
+	Resolved []string
+	Cnames   []string
+	Matches  bool
+	Cdn      string
+	Pop      string
+	Edge     string
+	Status   int
+	Headers  map[string]string
+}
+
+// EdgeGroup is one edge and every location it served, biggest first.
+type EdgeGroup struct {
+	Edge      string
+	Cdn       string
+	Pop       string
+	Ips       []string
+	Locations []string
+}
+
+// edgeHeaders are the response headers worth keeping for working out which
+// edge served a request, lower case.
+var edgeHeaders = []string{"cf-ray", "x-amz-cf-pop", "x-served-by", "x-edge-location", "x-pop", "x-azure-ref", "x-cache", "via", "server"}
+
+// EdgeReport maps every location with a finished dig or http test.
+func (j Job) EdgeReport() (*EdgeReport, error) {
+	digs, err := j.DigResults()
+	if err != nil {
+		return nil, err
+	}
+	https, err := j.HttpResults()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EdgeReport{Ip: j.Summary.Ip}
+	if j.Summary.Url.URL != nil {
+		report.Target = j.Summary.Url.Hostname()
+	}
+
+	cities := []string{}
+	for city := range digs {
+		cities = append(cities, city)
+	}
+	for city := range https {
+		if _, ok := digs[city]; !ok {
+			cities = append(cities, city)
+		}
+	}
+	sort.Strings(cities)
+
+	groups := map[string]*EdgeGroup{}
+	for _, city := range cities {
+		location := EdgeLocation{Location: city, Headers: map[string]string{}}
+
+		for _, answer := range digs[city].Answers {
+			switch strings.ToUpper(answer.Type) {
+			case "A", "AAAA":
+				location.Resolved = append(location.Resolved, answer.Data)
+			case "CNAME":
+				location.Cnames = append(location.Cnames, strings.TrimSuffix(answer.Data, "."))
+			}
+		}
+		// with nothing resolved, there's nothing to say it went elsewhere
+		location.Matches = report.Ip == "" || len(location.Resolved) == 0 || contains(location.Resolved, report.Ip)
+
+		if final := https[city].Final(); final != nil {
+			location.Status = final.Status
+			for name, value := range final.Headers {
+				if contains(edgeHeaders, strings.ToLower(name)) {
+					location.Headers[strings.ToLower(name)] = value
+				}
+			}
+		}
+		location.Cdn, location.Pop = identifyEdge(location.Headers)
+
+		switch {
+		case location.Pop != "":
+			location.Edge = strings.TrimSpace(location.Cdn + " " + location.Pop)
+		case len(location.Resolved) > 0:
+			location.Edge = strings.TrimSpace(location.Cdn + " " + location.Resolved[0])
+		case location.Cdn != "":
+			location.Edge = location.Cdn
+		default:
+			location.Edge = "unknown"
+		}
+
+		report.Locations = append(report.Locations, location)
+
+		group, ok := groups[location.Edge]
+		if !ok {
+			group = &EdgeGroup{Edge: location.Edge, Cdn: location.Cdn, Pop: location.Pop}
+			groups[location.Edge] = group
+		}
+		group.Locations = append(group.Locations, city)
+		for _, ip := range location.Resolved {
+			if !contains(group.Ips, ip) {
+				group.Ips = append(group.Ips, ip)
+			}
+		}
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.Ips)
+		report.Edges = append(report.Edges, *group)
+	}
+	sort.Slice(report.Edges, func(a, b int) bool {
+		ea, eb := report.Edges[a], report.Edges[b]
+		if len(ea.Locations) != len(eb.Locations) {
+			return len(ea.Locations) > len(eb.Locations)
+		}
+		return ea.Edge < eb.Edge
+	})
+
+	return report, nil
+}
+
+// identifyEdge works out the CDN and PoP from the response headers, for the
+// CDNs that say. Headers have to be lower case.
+func identifyEdge(headers map[string]string) (cdn, pop string) {
+	last := func(value, sep string) string {
+		parts := strings.Split(strings.TrimSpace(value), sep)
+		return strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	switch {
+	case headers["cf-ray"] != "":
+		// 7d1c8e5a9b0c1234-DEN
+		return "Cloudflare", strings.ToUpper(last(headers["cf-ray"], "-"))
+	case headers["x-amz-cf-pop"] != "":
+		// DEN50-C1
+		return "CloudFront", headers["x-amz-cf-pop"]
+	case headers["x-served-by"] != "" && strings.HasPrefix(headers["x-served-by"], "cache-"):
+		// cache-den8243-DEN, or a list when shielding; the last one is the
+		// edge closest to the client
+		return "Fastly", strings.ToUpper(last(last(headers["x-served-by"], ","), "-"))
+	case headers["x-azure-ref"] != "":
+		return "Azure Front Door", ""
+	case strings.Contains(strings.ToLower(headers["server"]), "akamai"):
+		return "Akamai", ""
+	case strings.EqualFold(headers["server"], "cloudflare"):
+		return "Cloudflare", ""
+	case strings.Contains(headers["via"], "CloudFront"):
+		return "CloudFront", ""
+	case headers["x-edge-location"] != "":
+		return "", headers["x-edge-location"]
+	case headers["x-pop"] != "":
+		return "", headers["x-pop"]
+	}
+
+	return "", ""
+}
@@ -0,0 +1,123 @@
+package gowup
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type EdgeTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestEdge(t *testing.T) {
+	suite.Run(t, new(EdgeTest))
+}
+
+func (e *EdgeTest) SetupTest() {
+	e.job = Job{}
+	json.Unmarshal([]byte(`{
+	    "request": {"url": "https://example.com", "ip": "104.16.1.1"},
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "dig": {"summary": {"answers": [
+	                    {"name": "example.com.", "type": "CNAME", "data": "example.com.cdn.cloudflare.net."},
+	                    {"name": "example.com.cdn.cloudflare.net.", "type": "A", "data": "104.16.1.1"}
+	                ]}},
+	                "http": {"summary": [{"status": 200, "headers": {"CF-Ray": "7d1c8e5a9b0c1234-den", "Server": "cloudflare", "Content-Type": "text/html"}}]}
+	            },
+	            "seattle": {
+	                "dig": {"summary": {"answers": [{"name": "example.com.", "type": "A", "data": "104.16.1.1"}]}},
+	                "http": {"summary": [{"status": 200, "headers": {"cf-ray": "7d1c8e5a9b0c9999-DEN"}}]}
+	            },
+	            "riga": {
+	                "dig": {"summary": {"answers": [{"name": "example.com.", "type": "A", "data": "104.16.2.2"}]}},
+	                "http": {"summary": [{"status": 301, "headers": {}}, {"status": 200, "headers": {"cf-ray": "7d1c8e5a9b0c5678-RIX"}}]}
+	            },
+	            "tokyo": {
+	                "dig": {"summary": {"answers": [{"name": "example.com.", "type": "A", "data": "203.0.113.9"}]}}
+	            }
+	        },
+	        "in_progress": [],
+	        "error": []
+	    }
+	}`), &e.job)
+}
+
+func (e *EdgeTest) TestLocations() {
+	report, err := e.job.EdgeReport()
+	e.NoError(err)
+
+	e.Equal("example.com", report.Target)
+	e.Equal("104.16.1.1", report.Ip)
+	e.Equal(4, len(report.Locations))
+
+	denver := report.Locations[0]
+	e.Equal("denver", denver.Location)
+	e.Equal([]string{"104.16.1.1"}, denver.Resolved)
+	e.Equal([]string{"example.com.cdn.cloudflare.net"}, denver.Cnames)
+	e.True(denver.Matches)
+	e.Equal("Cloudflare", denver.Cdn)
+	e.Equal("DEN", denver.Pop)
+	e.Equal("Cloudflare DEN", denver.Edge)
+	e.Equal(200, denver.Status)
+	e.Equal(map[string]string{"cf-ray": "7d1c8e5a9b0c1234-den", "server": "cloudflare"}, denver.Headers, "should only keep headers about the edge")
+
+	riga := report.Locations[1]
+	e.False(riga.Matches, "should flag locations that resolved somewhere else")
+	e.Equal("Cloudflare RIX", riga.Edge, "should use the final response")
+
+	tokyo := report.Locations[3]
+	e.Equal("203.0.113.9", tokyo.Edge, "should fall back to the resolved address")
+}
+
+func (e *EdgeTest) TestHttpOnly() {
+	e.job.Details.Done["sydney"] = map[string]interface{}{
+		"http": []interface{}{map[string]interface{}{"status": 200, "headers": map[string]interface{}{"cf-ray": "7d1c8e5a9b0c4321-SYD"}}},
+	}
+
+	report, err := e.job.EdgeReport()
+	e.NoError(err)
+
+	sydney := report.Locations[3]
+	e.Equal("sydney", sydney.Location)
+	e.Empty(sydney.Resolved)
+	e.True(sydney.Matches, "should not flag locations that didn't resolve anything")
+	e.Equal("Cloudflare SYD", sydney.Edge)
+}
+
+func (e *EdgeTest) TestGroups() {
+	report, _ := e.job.EdgeReport()
+
+	e.Equal([]EdgeGroup{
+		{Edge: "Cloudflare DEN", Cdn: "Cloudflare", Pop: "DEN", Ips: []string{"104.16.1.1"}, Locations: []string{"denver", "seattle"}},
+		{Edge: "203.0.113.9", Ips: []string{"203.0.113.9"}, Locations: []string{"tokyo"}},
+		{Edge: "Cloudflare RIX", Cdn: "Cloudflare", Pop: "RIX", Ips: []string{"104.16.2.2"}, Locations: []string{"riga"}},
+	}, report.Edges, "should group by edge, biggest first")
+}
+
+func (e *EdgeTest) TestIdentifyEdge() {
+	for _, c := range []struct {
+		headers  map[string]string
+		cdn, pop string
+	}{
+		{map[string]string{"x-amz-cf-pop": "DEN50-C1", "via": "1.1 abc.cloudfront.net (CloudFront)"}, "CloudFront", "DEN50-C1"},
+		{map[string]string{"x-served-by": "cache-iad-kiad7000025-IAD, cache-den8243-DEN"}, "Fastly", "DEN"},
+		{map[string]string{"server": "AkamaiGHost"}, "Akamai", ""},
+		{map[string]string{"via": "1.1 abc.cloudfront.net (CloudFront)"}, "CloudFront", ""},
+		{map[string]string{"x-pop": "ams1"}, "", "ams1"},
+		{map[string]string{"server": "nginx"}, "", ""},
+	} {
+		cdn, pop := identifyEdge(c.headers)
+		e.Equal(c.cdn, cdn)
+		e.Equal(c.pop, pop)
+	}
+}
+
+func (e *EdgeTest) TestBadResult() {
+	e.job.Details.Done["denver"]["http"] = "nonsense"
+	_, err := e.job.EdgeReport()
+	e.Error(err)
+}
@@ -0,0 +1,238 @@
+package gowup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ellotheth/gowup/internal/atomicfile"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// expiryWindow is how close to expiry a job has to be before the client
+// warns about it, unless Expiry says otherwise.
+const expiryWindow = 24 * time.Hour
+
+// Expiry says what the client does when it fetches a job that's about to
+// expire. Each job is warned about and archived at most once, however often
+// it's fetched, so polling in Wait doesn't repeat either. With no Expiry, the
+// client does nothing.
+type Expiry struct {
+	// Window is how close to expiry counts as "about to"; a day if it's
+	// zero.
+	Window time.Duration
+	// Archive, if set, gets a copy of every expiring job that's fetched once
+	// it's finished.
+	Archive Archive
+	// Warn is called with each expiring job.
+	Warn func(job *Job)
+	// OnError is called when a job can't be archived. It'll be tried again
+	// the next time it's fetched.
+	OnError func(id JobID, err error)
+
+	mu       sync.Mutex
+	warned   map[JobID]bool
+	archived map[JobID]bool
+}
+
+// Archive is somewhere to keep jobs after the API has forgotten them. Jobs
+// are stored as the JSON the API sent.
+type Archive interface {
+	Store(id JobID, raw []byte) error
+	Has(id JobID) bool
+}
+
+// DirArchive keeps each job in a directory as <id>.json.
+type DirArchive string
+
+func (d DirArchive) path(id JobID) string {
+	return filepath.Join(string(d), id.String()+".json")
+}
+
+// Store writes the job, replacing any earlier copy. The file only appears
+// once it's complete, so a crash can't leave half a job behind.
+func (d DirArchive) Store(id JobID, raw []byte) error {
+	if err := id.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(string(d), 0700); err != nil {
+		return err
+	}
+
+	return atomicfile.Write(d.path(id), raw, 0600)
+}
+
+// Has is true if the job has been archived.
+func (d DirArchive) Has(id JobID) bool {
+	if id.Validate() != nil {
+		return false
+	}
+	_, err := os.Stat(d.path(id))
+	return err == nil
+}
+
+// Load reads an archived job.
+func (d DirArchive) Load(id JobID) (*Job, error) {
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadFile(d.path(id))
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	if err := json.Unmarshal(raw, job); err != nil {
+		return nil, err
+	}
+	job.Id = id
+	job.Summary.Id = id
+
+	return job, nil
+}
+
+// Expiring is true if the job expires within window, or already has. Jobs
+// without an expiry time never expire.
+func (s JobSummary) Expiring(window time.Duration) bool {
+	return !s.ExpireTime.IsZero() && time.Until(s.ExpireTime.Time) <= window
+}
+
+// checkExpiry warns about a job that's about to expire, and archives it if
+// there's somewhere to put it and it's finished. Neither gets in the way of
+// returning the job.
+func (api WIU) checkExpiry(job *Job, raw []byte) {
+	expiry := api.Expiry
+	if expiry == nil {
+		return
+	}
+
+	window := expiry.Window
+	if window <= 0 {
+		window = expiryWindow
+	}
+
+	if !job.Summary.Expiring(window) {
+		return
+	}
+
+	expiry.mu.Lock()
+	if expiry.warned == nil {
+		expiry.warned, expiry.archived = map[JobID]bool{}, map[JobID]bool{}
+	}
+	warn := expiry.Warn != nil && !expiry.warned[job.Id]
+	expiry.warned[job.Id] = true
+
+	// a running job would leave a partial copy that the sweeper then skips
+	archive := expiry.Archive != nil && !expiry.archived[job.Id] && len(job.Details.NotDone) == 0
+	if archive {
+		expiry.archived[job.Id] = true
+	}
+	expiry.mu.Unlock()
+
+	if warn {
+		expiry.Warn(job)
+	}
+
+	if archive {
+		if err := expiry.Archive.Store(job.Id, raw); err != nil {
+			if expiry.OnError != nil {
+				expiry.OnError(job.Id, err)
+			}
+
+			expiry.mu.Lock()
+			delete(expiry.archived, job.Id)
+			expiry.mu.Unlock()
+		}
+	}
+}
+
+// Sweeper archives jobs before they expire. Every Interval it lists the
+// account's jobs and stores any that will expire within Window, have
+// finished, and aren't in the archive yet. Window defaults to a day and
+// Interval to an hour. OnError is called with whatever goes wrong in a sweep
+// that Run does.
+type Sweeper struct {
+	Api      WIU
+	Archive  Archive
+	Window   time.Duration
+	Interval time.Duration
+	OnError  func(err error)
+}
+
+// Run sweeps straight away, then every Interval until ctx is done. Failed
+// sweeps go to OnError and are tried again next time.
+func (s Sweeper) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep does one pass, returning the IDs of the jobs it archived. A job that
+// can't be fetched or stored doesn't stop the rest; the errors come back
+// together.
+func (s Sweeper) Sweep(ctx context.Context) ([]JobID, error) {
+	if s.Archive == nil {
+		return nil, &Error{msg: "Nowhere to archive jobs"}
+	}
+
+	window := s.Window
+	if window <= 0 {
+		window = expiryWindow
+	}
+
+	jobs, err := s.Api.Jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []JobID
+	var errs []error
+	for id, summary := range jobs {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		if !summary.Expiring(window) || s.Archive.Has(id) {
+			continue
+		}
+
+		job, raw, err := s.Api.fetchJob(ctx, id)
+		if err == nil && len(job.Details.NotDone) > 0 {
+			// still running, so try again next time
+			continue
+		}
+		if err == nil {
+			err = s.Archive.Store(id, raw)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		archived = append(archived, id)
+	}
+	sort.Slice(archived, func(a, b int) bool { return archived[a] < archived[b] })
+
+	return archived, errors.Join(errs...)
+}
@@ -0,0 +1,234 @@
+package gowup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	soonID    = JobID("534419e98c3dcffa6170aea1")
+	laterID   = JobID("534419e98c3dcffa6170aea2")
+	savedID   = JobID("534419e98c3dcffa6170aea3")
+	runningID = JobID("534419e98c3dcffa6170aea4")
+)
+
+type ExpiryTest struct {
+	suite.Suite
+	api     WIU
+	archive DirArchive
+	expiry  map[JobID]time.Time
+	mu      sync.Mutex
+	fetched []string
+}
+
+func TestExpiry(t *testing.T) {
+	suite.Run(t, new(ExpiryTest))
+}
+
+func (e *ExpiryTest) SetupTest() {
+	e.api = WIU{Client: "herp", Token: "derp", Expiry: &Expiry{Warn: func(*Job) {}}}
+	e.archive = DirArchive(e.T().TempDir())
+	e.fetched = nil
+	e.expiry = map[JobID]time.Time{
+		soonID:  time.Now().Add(time.Hour),
+		laterID: time.Now().Add(72 * time.Hour),
+		savedID: time.Now().Add(time.Hour),
+	}
+}
+
+// server lists the jobs in e.expiry and serves each of them with one ping
+func (e *ExpiryTest) server() *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jobs" {
+			jobs := []string{}
+			for id, expiry := range e.expiry {
+				jobs = append(jobs, fmt.Sprintf(`"%s": {"expiry": %d}`, id, expiry.Unix()))
+			}
+			w.Write([]byte("{" + strings.Join(jobs, ", ") + "}"))
+			return
+		}
+
+		id := JobID(strings.TrimPrefix(r.URL.Path, "/jobs/"))
+		e.mu.Lock()
+		e.fetched = append(e.fetched, id.String())
+		e.mu.Unlock()
+
+		running := `[]`
+		if id == runningID {
+			running = `{"riga": {"ping": {}}}`
+		}
+		fmt.Fprintf(w, `{"request": {"url": "https://google.com", "expiry": %d}, "response": {"complete": {"denver": {"ping": {"summary": {"avg": 12.5}}}}, "error": [], "in_progress": %s}}`, e.expiry[id].Unix(), running)
+	}))
+	apiEntryPoint = server.URL
+	return server
+}
+
+func (e *ExpiryTest) TestExpiring() {
+	e.True(JobSummary{ExpireTime: Time{Time: time.Now().Add(time.Hour)}}.Expiring(2 * time.Hour))
+	e.True(JobSummary{ExpireTime: Time{Time: time.Now().Add(-time.Hour)}}.Expiring(2*time.Hour), "should count expired jobs")
+	e.False(JobSummary{ExpireTime: Time{Time: time.Now().Add(3 * time.Hour)}}.Expiring(2 * time.Hour))
+	e.False(JobSummary{}.Expiring(2*time.Hour), "should not expire without an expiry time")
+}
+
+func (e *ExpiryTest) TestWarns() {
+	defer e.server().Close()
+
+	var warned []JobID
+	e.api.Expiry.Warn = func(job *Job) { warned = append(warned, job.Id) }
+
+	_, err := e.api.Job(soonID)
+	e.NoError(err)
+	_, err = e.api.Job(laterID)
+	e.NoError(err)
+	_, err = e.api.Job(soonID)
+	e.NoError(err)
+
+	e.Equal([]JobID{soonID}, warned, "should only warn once about jobs about to expire")
+}
+
+func (e *ExpiryTest) TestQuietByDefault() {
+	defer e.server().Close()
+	e.api.Expiry = nil
+
+	_, err := e.api.Job(soonID)
+	e.NoError(err)
+}
+
+func (e *ExpiryTest) TestArchivesOnFetch() {
+	defer e.server().Close()
+	e.api.Expiry.Archive = e.archive
+
+	_, err := e.api.Job(soonID)
+	e.NoError(err)
+	_, err = e.api.Job(laterID)
+	e.NoError(err)
+
+	e.True(e.archive.Has(soonID), "should archive jobs about to expire")
+	e.False(e.archive.Has(laterID), "should leave other jobs alone")
+
+	job, err := e.archive.Load(soonID)
+	e.NoError(err)
+	e.Equal(soonID, job.Id)
+	e.Equal("https://google.com", job.Summary.Url.String())
+	e.Equal(12.5, job.Details.Done["denver"]["ping"].(map[string]interface{})["avg"], "should keep the results")
+}
+
+func (e *ExpiryTest) TestArchivesOnce() {
+	defer e.server().Close()
+	e.expiry[runningID] = time.Now().Add(time.Hour)
+
+	stored := map[JobID]int{}
+	e.api.Expiry.Archive = archiveFunc(func(id JobID, raw []byte) error {
+		stored[id]++
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := e.api.Job(soonID)
+		e.NoError(err)
+		_, err = e.api.Job(runningID)
+		e.NoError(err)
+	}
+
+	e.Equal(map[JobID]int{soonID: 1}, stored, "should archive finished jobs once, and not running ones")
+}
+
+func (e *ExpiryTest) TestSweep() {
+	defer e.server().Close()
+	e.NoError(e.archive.Store(savedID, []byte(`{}`)))
+
+	sweeper := Sweeper{Api: e.api, Archive: e.archive, Window: 2 * time.Hour}
+
+	archived, err := sweeper.Sweep(context.Background())
+	e.NoError(err)
+	e.Equal([]JobID{soonID}, archived, "should archive jobs expiring within the window")
+	e.Equal([]string{soonID.String()}, e.fetched, "should not fetch jobs it doesn't need")
+
+	archived, err = sweeper.Sweep(context.Background())
+	e.NoError(err)
+	e.Empty(archived, "should not archive jobs twice")
+
+	_, err = Sweeper{Api: e.api}.Sweep(context.Background())
+	e.Error(err, "should need an archive")
+}
+
+func (e *ExpiryTest) TestArchiveErrors() {
+	defer e.server().Close()
+
+	var failed []JobID
+	e.api.Expiry.OnError = func(id JobID, err error) { failed = append(failed, id) }
+	e.api.Expiry.Archive = archiveFunc(func(id JobID, raw []byte) error { return errors.New("nope") })
+
+	_, err := e.api.Job(soonID)
+	e.NoError(err, "should still return the job")
+	_, err = e.api.Job(soonID)
+	e.NoError(err)
+
+	e.Equal([]JobID{soonID, soonID}, failed, "should report each failure and try again")
+}
+
+func (e *ExpiryTest) TestSweepRunning() {
+	defer e.server().Close()
+	e.expiry = map[JobID]time.Time{runningID: time.Now().Add(time.Hour)}
+
+	archived, err := Sweeper{Api: e.api, Archive: e.archive}.Sweep(context.Background())
+	e.NoError(err)
+	e.Empty(archived, "should wait for running jobs to finish")
+	e.False(e.archive.Has(runningID))
+}
+
+func (e *ExpiryTest) TestRun() {
+	defer e.server().Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	var errs []error
+	sweeper := Sweeper{Api: e.api, Archive: e.archive, Window: 2 * time.Hour, Interval: time.Millisecond}
+	sweeper.OnError = func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	err := sweeper.Run(ctx)
+	e.Equal(context.DeadlineExceeded, err, "should run until the context is done")
+	e.True(e.archive.Has(soonID))
+
+	sweeper.Archive = nil
+	sweeper.Run(ctx)
+	mu.Lock()
+	defer mu.Unlock()
+	e.NotEmpty(errs, "should report failed sweeps")
+}
+
+func (e *ExpiryTest) TestDirArchive() {
+	e.Error(e.archive.Store("../../etc/passwd", []byte(`{}`)), "should reject bad ids")
+	e.False(e.archive.Has("../../etc/passwd"))
+	e.False(e.archive.Has(soonID))
+
+	_, err := e.archive.Load(soonID)
+	e.Error(err, "should fail for jobs that aren't there")
+
+	e.NoError(e.archive.Store(soonID, []byte(`{"request": {}}`)))
+	e.NoError(e.archive.Store(soonID, []byte(`{"request": {"ip": "1.2.3.4"}}`)), "should replace earlier copies")
+
+	job, err := e.archive.Load(soonID)
+	e.NoError(err)
+	e.Equal("1.2.3.4", job.Summary.Ip)
+}
+
+// archiveFunc stores with a function, and never has anything
+type archiveFunc func(id JobID, raw []byte) error
+
+func (f archiveFunc) Store(id JobID, raw []byte) error { return f(id, raw) }
+func (f archiveFunc) Has(id JobID) bool                { return false }
@@ -0,0 +1,131 @@
+// Package export flattens job results into one record per location, test and
+// metric, and writes them out as JSON Lines, CSV or InfluxDB line protocol.
+// It also turns locations and job results into GeoJSON for map widgets.
+package export
+
+import (
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/schedule"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record is one measurement from one location.
+type Record struct {
+	JobId     string    `json:"job_id"`
+	Target    string    `json:"target"`
+	Time      time.Time `json:"time"`
+	Location  string    `json:"location"`
+	Country   string    `json:"country"`
+	Continent string    `json:"continent"`
+	Test      string    `json:"test"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+}
+
+// Writer writes records in some format.
+type Writer interface {
+	Write(records []Record) error
+}
+
+// Flatten turns a job into records, sorted by location, test and metric.
+// locations is the catalog from Locations(), used to fill in the country and
+// continent of each record; it can be nil. A test that errored gets an
+// "error" metric of 1.
+func Flatten(id gowup.JobID, job *gowup.Job, locations []gowup.Location) ([]Record, error) {
+	catalog := map[string]gowup.Location{}
+	for _, location := range locations {
+		catalog[location.Name] = location
+	}
+
+	target := ""
+	if job.Summary.Url.URL != nil {
+		target = job.Summary.Url.String()
+	}
+
+	var records []Record
+	add := func(city, test, metric string, value float64) {
+		records = append(records, Record{
+			JobId:     id.String(),
+			Target:    target,
+			Time:      job.Summary.StartTime.Time,
+			Location:  city,
+			Country:   catalog[city].Country,
+			Continent: catalog[city].Continent,
+			Test:      test,
+			Metric:    metric,
+			Value:     value,
+		})
+	}
+
+	pings, err := job.PingResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, ping := range pings {
+		add(city, "ping", "transmitted", float64(ping.Transmitted))
+		add(city, "ping", "received", float64(ping.Received))
+		add(city, "ping", "loss", ping.Loss)
+		add(city, "ping", "rtt_min", ping.Min)
+		add(city, "ping", "rtt_avg", ping.Avg)
+		add(city, "ping", "rtt_max", ping.Max)
+		add(city, "ping", "rtt_mdev", ping.Mdev)
+	}
+
+	https, err := job.HttpResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, http := range https {
+		if final := http.Final(); final != nil {
+			add(city, "http", "status", float64(final.Status))
+			add(city, "http", "redirects", float64(len(http.Redirects())))
+		}
+		add(city, "http", "time", http.Time())
+	}
+
+	digs, err := job.DigResults()
+	if err != nil {
+		return nil, err
+	}
+	for city, dig := range digs {
+		add(city, "dig", "answers", float64(len(dig.Answers)))
+	}
+
+	for city, tests := range job.Details.Error {
+		for test := range tests {
+			add(city, test, "error", 1)
+		}
+	}
+
+	sort.SliceStable(records, func(a, b int) bool {
+		ra, rb := records[a], records[b]
+		if ra.Location != rb.Location {
+			return ra.Location < rb.Location
+		}
+		if ra.Test != rb.Test {
+			return ra.Test < rb.Test
+		}
+		return ra.Metric < rb.Metric
+	})
+
+	return records, nil
+}
+
+// Sink writes every job the scheduler finishes to w. Runs finish
+// concurrently, so writes are serialized.
+func Sink(w Writer, locations []gowup.Location) schedule.Sink {
+	var mu sync.Mutex
+
+	return schedule.SinkFunc(func(check schedule.Check, id gowup.JobID, job *gowup.Job) error {
+		records, err := Flatten(id, job, locations)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return w.Write(records)
+	})
+}
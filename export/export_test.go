@@ -0,0 +1,105 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/schedule"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ExportTest struct {
+	suite.Suite
+	job       *gowup.Job
+	locations []gowup.Location
+}
+
+func TestExport(t *testing.T) {
+	suite.Run(t, new(ExportTest))
+}
+
+func (e *ExportTest) SetupTest() {
+	e.job = &gowup.Job{}
+	json.Unmarshal([]byte(`{
+	    "request": {"url": "https://google.com", "start_time": 1404053589},
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "ping": {"summary": {"transmitted": 4, "received": 4, "loss": 0, "min": 1, "avg": 2, "max": 3, "mdev": 0.5}},
+	                "http": {"summary": [{"status": 301, "headers": {"Location": "https://www.google.com/"}, "time": 10}, {"status": 200, "time": 20}]}
+	            },
+	            "riga": {
+	                "dig": {"summary": {"answers": [{"data": "1.2.3.4"}, {"data": "5.6.7.8"}]}}
+	            }
+	        },
+	        "error": {"riga": {"ping": {"summary": "timed out"}}},
+	        "in_progress": []
+	    }
+	}`), e.job)
+
+	e.locations = []gowup.Location{
+		{Name: "denver", Country: "United States", Continent: "North America"},
+		{Name: "riga", Country: "Latvia", Continent: "Europe"},
+	}
+}
+
+func (e *ExportTest) TestFlatten() {
+	records, err := Flatten("aa", e.job, e.locations)
+	e.NoError(err, "should not return an error")
+	e.Equal(12, len(records), "should have a record per metric")
+
+	first := records[0]
+	e.Equal(Record{
+		JobId:     "aa",
+		Target:    "https://google.com",
+		Time:      time.Unix(1404053589, 0),
+		Location:  "denver",
+		Country:   "United States",
+		Continent: "North America",
+		Test:      "http",
+		Metric:    "redirects",
+		Value:     1,
+	}, first, "should sort by location, test and metric")
+
+	last := records[len(records)-1]
+	e.Equal("riga", last.Location)
+	e.Equal("ping", last.Test)
+	e.Equal("error", last.Metric, "should record errored tests")
+}
+
+func (e *ExportTest) TestFlattenRedirects() {
+	job := &gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{
+		"denver": {"http": []interface{}{}},
+		"riga":   {"http": []interface{}{map[string]interface{}{"status": 200, "time": 10}}},
+	}}}
+
+	records, err := Flatten("aa", job, nil)
+	e.NoError(err)
+
+	metrics := map[string]float64{}
+	for _, record := range records {
+		metrics[record.Location+" "+record.Metric] = record.Value
+	}
+	e.Equal(map[string]float64{
+		"denver time":    0,
+		"riga redirects": 0,
+		"riga status":    200,
+		"riga time":      10,
+	}, metrics, "should only count redirects for a response chain")
+}
+
+func (e *ExportTest) TestFlattenWithoutCatalog() {
+	records, err := Flatten("aa", &gowup.Job{}, nil)
+	e.NoError(err, "should handle empty jobs")
+	e.Empty(records)
+}
+
+func (e *ExportTest) TestSink() {
+	var out bytes.Buffer
+	sink := Sink(NewJsonLines(&out), e.locations)
+
+	e.NoError(sink.Send(schedule.Check{Name: "google"}, "aa", e.job))
+	e.Equal(12, bytes.Count(out.Bytes(), []byte("\n")), "should write every record")
+}
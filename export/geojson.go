@@ -0,0 +1,155 @@
+package export
+
+import (
+	"fmt"
+	"github.com/ellotheth/gowup"
+	"sort"
+	"strconv"
+)
+
+// FeatureCollection is a GeoJSON feature collection of points. Marshal it
+// with encoding/json.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON point with properties.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Point                  `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Point coordinates are longitude then latitude, as GeoJSON wants them.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// LocationsGeoJson turns the catalog from Locations() into a point per
+// source location.
+func LocationsGeoJson(locations []gowup.Location) (*FeatureCollection, error) {
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	for _, location := range locations {
+		feature, err := newFeature(location)
+		if err != nil {
+			return nil, err
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+
+	return collection, nil
+}
+
+// JobGeoJson puts the results for every location in the job on the map.
+// Each point gets the location's catalog properties plus:
+//
+//	result   complete, in_progress or error
+//	latency  average ping time, or the HTTP time if there's no ping
+//	loss     ping packet loss
+//	status   final HTTP status
+//	errors   the tests that errored
+//
+// Results are joined to the catalog by location name; locations that aren't
+// in the catalog have no coordinates, so they're left out.
+func JobGeoJson(job *gowup.Job, locations []gowup.Location) (*FeatureCollection, error) {
+	catalog := map[string]gowup.Location{}
+	for _, location := range locations {
+		catalog[location.Name] = location
+	}
+
+	pings, err := job.PingResults()
+	if err != nil {
+		return nil, err
+	}
+	https, err := job.HttpResults()
+	if err != nil {
+		return nil, err
+	}
+
+	cities := map[string]bool{}
+	for _, detail := range []gowup.JobDetail{job.Details.Done, job.Details.NotDone, job.Details.Error} {
+		for city := range detail {
+			cities[city] = true
+		}
+	}
+
+	names := make([]string, 0, len(cities))
+	for city := range cities {
+		names = append(names, city)
+	}
+	sort.Strings(names)
+
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	for _, city := range names {
+		location, ok := catalog[city]
+		if !ok {
+			continue
+		}
+
+		feature, err := newFeature(location)
+		if err != nil {
+			return nil, err
+		}
+		props := feature.Properties
+
+		props["result"] = "complete"
+		if _, ok := job.Details.NotDone[city]; ok {
+			props["result"] = "in_progress"
+		}
+		if tests, ok := job.Details.Error[city]; ok {
+			props["result"] = "error"
+
+			errors := make([]string, 0, len(tests))
+			for test := range tests {
+				errors = append(errors, test)
+			}
+			sort.Strings(errors)
+			props["errors"] = errors
+		}
+
+		if http, ok := https[city]; ok {
+			props["latency"] = http.Time()
+			if final := http.Final(); final != nil {
+				props["status"] = final.Status
+			}
+		}
+		if ping, ok := pings[city]; ok {
+			// with no replies there's no ping latency, so keep the http time
+			// if there is one
+			if ping.Loss < 100 {
+				props["latency"] = ping.Avg
+			}
+			props["loss"] = ping.Loss
+		}
+
+		collection.Features = append(collection.Features, feature)
+	}
+
+	return collection, nil
+}
+
+func newFeature(location gowup.Location) (Feature, error) {
+	lat, err := strconv.ParseFloat(location.Lat, 64)
+	if err != nil {
+		return Feature{}, fmt.Errorf("invalid latitude for %s: %s", location.Name, err)
+	}
+	lon, err := strconv.ParseFloat(location.Lon, 64)
+	if err != nil {
+		return Feature{}, fmt.Errorf("invalid longitude for %s: %s", location.Name, err)
+	}
+
+	return Feature{
+		Type:     "Feature",
+		Geometry: Point{Type: "Point", Coordinates: [2]float64{lon, lat}},
+		Properties: map[string]interface{}{
+			"name":      location.Name,
+			"title":     location.Title,
+			"city":      location.City,
+			"state":     location.State,
+			"country":   location.Country,
+			"continent": location.Continent,
+		},
+	}, nil
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/ellotheth/gowup"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type GeoJsonTest struct {
+	suite.Suite
+	locations []gowup.Location
+}
+
+func TestGeoJson(t *testing.T) {
+	suite.Run(t, new(GeoJsonTest))
+}
+
+func (g *GeoJsonTest) SetupTest() {
+	g.locations = []gowup.Location{
+		{Name: "denver", Title: "Denver", City: "Denver", State: "Colorado", Country: "United States", Lat: "39.7392", Lon: "-104.9903", Continent: "North America"},
+		{Name: "riga", Title: "Riga", City: "Riga", Country: "Latvia", Lat: "56.9496", Lon: "24.1052", Continent: "Europe"},
+		{Name: "tokyo", Title: "Tokyo", City: "Tokyo", Country: "Japan", Lat: "35.6762", Lon: "139.6503", Continent: "Asia"},
+	}
+}
+
+func (g *GeoJsonTest) TestLocations() {
+	collection, err := LocationsGeoJson(g.locations[:1])
+	g.NoError(err, "should not return an error")
+
+	raw, _ := json.Marshal(collection)
+	g.JSONEq(`{
+	    "type": "FeatureCollection",
+	    "features": [{
+	        "type": "Feature",
+	        "geometry": {"type": "Point", "coordinates": [-104.9903, 39.7392]},
+	        "properties": {
+	            "name": "denver",
+	            "title": "Denver",
+	            "city": "Denver",
+	            "state": "Colorado",
+	            "country": "United States",
+	            "continent": "North America"
+	        }
+	    }]
+	}`, string(raw))
+}
+
+func (g *GeoJsonTest) TestBadCoordinates() {
+	_, err := LocationsGeoJson([]gowup.Location{{Name: "nowhere", Lat: "north", Lon: "0"}})
+	g.Error(err, "should reject coordinates that aren't numbers")
+}
+
+func (g *GeoJsonTest) TestNoReplies() {
+	job := &gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{
+		"denver": {
+			"ping": map[string]interface{}{"transmitted": 3, "received": 0, "loss": 100},
+			"http": []interface{}{map[string]interface{}{"status": 200, "time": 80}},
+		},
+		"riga": {"ping": map[string]interface{}{"transmitted": 3, "received": 0, "loss": 100}},
+	}}}
+
+	collection, err := JobGeoJson(job, g.locations)
+	g.NoError(err)
+
+	denver := collection.Features[0].Properties
+	g.Equal(float64(80), denver["latency"], "should fall back to the http time")
+	g.Equal(float64(100), denver["loss"])
+
+	_, ok := collection.Features[1].Properties["latency"]
+	g.False(ok, "should leave out latency without any replies")
+}
+
+func (g *GeoJsonTest) TestJob() {
+	job := &gowup.Job{Details: gowup.JobDetails{
+		Done: gowup.JobDetail{
+			"denver": {
+				"ping": map[string]interface{}{"avg": 12.5, "loss": 25},
+				"http": []interface{}{map[string]interface{}{"status": 200, "time": 80}},
+			},
+			"mars": {"ping": map[string]interface{}{"avg": 1}},
+		},
+		NotDone: gowup.JobDetail{"riga": {"ping": nil}},
+		Error:   gowup.JobDetail{"tokyo": {"ping": nil, "http": nil}},
+	}}
+
+	collection, err := JobGeoJson(job, g.locations)
+	g.NoError(err, "should not return an error")
+	g.Equal(3, len(collection.Features), "should skip locations without coordinates")
+
+	denver := collection.Features[0].Properties
+	g.Equal("complete", denver["result"])
+	g.Equal(12.5, denver["latency"], "should prefer ping latency")
+	g.Equal(float64(25), denver["loss"])
+	g.Equal(200, denver["status"])
+
+	g.Equal("in_progress", collection.Features[1].Properties["result"])
+
+	tokyo := collection.Features[2].Properties
+	g.Equal("error", tokyo["result"])
+	g.Equal([]string{"http", "ping"}, tokyo["errors"])
+	g.Equal([2]float64{139.6503, 35.6762}, collection.Features[2].Geometry.Coordinates)
+}
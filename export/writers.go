@@ -0,0 +1,145 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JsonLines writes one JSON object per record, one per line.
+type JsonLines struct {
+	encoder *json.Encoder
+}
+
+func NewJsonLines(w io.Writer) *JsonLines {
+	return &JsonLines{encoder: json.NewEncoder(w)}
+}
+
+func (j *JsonLines) Write(records []Record) error {
+	for _, record := range records {
+		if err := j.encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CsvColumns is the header row, in order. It doesn't change, so files from
+// different jobs can be concatenated.
+var CsvColumns = []string{"job_id", "target", "time", "location", "country", "continent", "test", "metric", "value"}
+
+// Csv writes records as CSV. The header goes out before the first record.
+type Csv struct {
+	writer *csv.Writer
+	header bool
+}
+
+func NewCsv(w io.Writer) *Csv {
+	return &Csv{writer: csv.NewWriter(w)}
+}
+
+func (c *Csv) Write(records []Record) error {
+	if !c.header {
+		if err := c.writer.Write(CsvColumns); err != nil {
+			return err
+		}
+		c.header = true
+	}
+
+	for _, r := range records {
+		row := []string{
+			r.JobId,
+			r.Target,
+			r.Time.UTC().Format(time.RFC3339),
+			r.Location,
+			r.Country,
+			r.Continent,
+			r.Test,
+			r.Metric,
+			strconv.FormatFloat(r.Value, 'f', -1, 64),
+		}
+		if err := c.writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// Influx writes records as InfluxDB line protocol. Every test is its own
+// measurement ("wup_ping", "wup_http"), each metric is a field, and
+// everything else is a tag, except the job ID: that's a string field, since
+// a tag per job would make a new series every run. Records that share a job,
+// location and test go on the same line. Records without a time leave the
+// timestamp to the server.
+type Influx struct {
+	w io.Writer
+}
+
+func NewInflux(w io.Writer) *Influx {
+	return &Influx{w: w}
+}
+
+func (i *Influx) Write(records []Record) error {
+	type line struct {
+		tags   string
+		fields []string
+		time   time.Time
+	}
+
+	var lines []*line
+	index := map[string]*line{}
+
+	for _, r := range records {
+		tags := []string{escapeTag("wup_" + r.Test)}
+		for _, tag := range [][2]string{
+			{"continent", r.Continent},
+			{"country", r.Country},
+			{"location", r.Location},
+			{"target", r.Target},
+		} {
+			// line protocol doesn't allow empty tag values
+			if tag[1] != "" {
+				tags = append(tags, tag[0]+"="+escapeTag(tag[1]))
+			}
+		}
+
+		key := strings.Join(tags, ",") + " " + r.JobId
+		l, ok := index[key]
+		if !ok {
+			l = &line{tags: strings.Join(tags, ","), time: r.Time}
+			if r.JobId != "" {
+				l.fields = append(l.fields, "job_id="+escapeField(r.JobId))
+			}
+			index[key] = l
+			lines = append(lines, l)
+		}
+		l.fields = append(l.fields, escapeTag(r.Metric)+"="+strconv.FormatFloat(r.Value, 'f', -1, 64))
+	}
+
+	for _, l := range lines {
+		text := l.tags + " " + strings.Join(l.fields, ",")
+		if !l.time.IsZero() {
+			text += " " + strconv.FormatInt(l.time.UnixNano(), 10)
+		}
+		if _, err := fmt.Fprintln(i.w, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escapeField quotes a string field value.
+func escapeField(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func escapeTag(value string) string {
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(value)
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WritersTest struct {
+	suite.Suite
+	records []Record
+}
+
+func TestWriters(t *testing.T) {
+	suite.Run(t, new(WritersTest))
+}
+
+func (w *WritersTest) SetupTest() {
+	when := time.Unix(1404053589, 0)
+	w.records = []Record{
+		{JobId: "aa", Target: "https://google.com", Time: when, Location: "denver", Country: "United States", Continent: "North America", Test: "ping", Metric: "loss", Value: 0},
+		{JobId: "aa", Target: "https://google.com", Time: when, Location: "denver", Country: "United States", Continent: "North America", Test: "ping", Metric: "rtt_avg", Value: 2.5},
+		{JobId: "aa", Target: "https://google.com", Time: when, Location: "riga", Test: "http", Metric: "status", Value: 200},
+	}
+}
+
+func (w *WritersTest) TestJsonLines() {
+	var out bytes.Buffer
+	w.NoError(NewJsonLines(&out).Write(w.records[:1]))
+	w.Equal(`{"job_id":"aa","target":"https://google.com","time":"`+w.records[0].Time.Format(time.RFC3339Nano)+`","location":"denver","country":"United States","continent":"North America","test":"ping","metric":"loss","value":0}`+"\n", out.String())
+}
+
+func (w *WritersTest) TestCsv() {
+	var out bytes.Buffer
+	writer := NewCsv(&out)
+	w.NoError(writer.Write(w.records[:1]))
+	w.NoError(writer.Write(w.records[2:]))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	w.Equal(3, len(lines), "should only write the header once")
+	w.Equal("job_id,target,time,location,country,continent,test,metric,value", lines[0])
+	w.Equal("aa,https://google.com,2014-06-29T14:53:09Z,denver,United States,North America,ping,loss,0", lines[1])
+	w.Equal("aa,https://google.com,2014-06-29T14:53:09Z,riga,,,http,status,200", lines[2])
+}
+
+func (w *WritersTest) TestInflux() {
+	var out bytes.Buffer
+	w.NoError(NewInflux(&out).Write(w.records))
+
+	w.Equal(
+		`wup_ping,continent=North\ America,country=United\ States,location=denver,target=https://google.com job_id="aa",loss=0,rtt_avg=2.5 1404053589000000000`+"\n"+
+			`wup_http,location=riga,target=https://google.com job_id="aa",status=200 1404053589000000000`+"\n",
+		out.String(),
+		"should group fields by job, location and test, and skip empty tags",
+	)
+}
+
+func (w *WritersTest) TestInfluxJobs() {
+	records := []Record{w.records[2], w.records[2], {Location: "riga", Test: "http", Metric: "status", Value: 500}}
+	records[1].JobId, records[1].Time = `b"b`, time.Time{}
+
+	var out bytes.Buffer
+	w.NoError(NewInflux(&out).Write(records))
+
+	w.Equal(
+		`wup_http,location=riga,target=https://google.com job_id="aa",status=200 1404053589000000000`+"\n"+
+			`wup_http,location=riga,target=https://google.com job_id="b\"b",status=200`+"\n"+
+			`wup_http,location=riga status=500`+"\n",
+		out.String(),
+		"should keep jobs on separate lines, and leave out missing times",
+	)
+}
@@ -0,0 +1,131 @@
+package gowup
+
+import (
+	"sort"
+	"strings"
+)
+
+// FastResult is the summary of a fast (download speed) test from one
+// location. Speed is in Unit, which defaults to Mbps; Bytes is how much was
+// downloaded, over Duration milliseconds.
+type FastResult struct {
+	Speed    float64 `json:"speed"`
+	Unit     string  `json:"unit"`
+	Bytes    int64   `json:"bytes"`
+	Duration float64 `json:"duration"`
+}
+
+// Mbps is the throughput in megabits per second, whatever unit it came in.
+// Without a speed, it's worked out from the bytes and duration.
+func (f FastResult) Mbps() (float64, error) {
+	if f.Speed == 0 && f.Bytes > 0 && f.Duration > 0 {
+		return float64(f.Bytes) * 8 / 1e6 / (f.Duration / 1000), nil
+	}
+
+	scale, err := mbpsScale(f.Unit)
+	if err != nil {
+		return 0, err
+	}
+	return f.Speed * scale, nil
+}
+
+// mbpsScale converts a speed unit to Mbps. Bits are lower case b and bytes
+// upper case B, so Mbps and MB/s are different things; the prefixes are
+// decimal, like everyone selling bandwidth uses.
+func mbpsScale(unit string) (float64, error) {
+	unit = strings.TrimSpace(unit)
+	if unit == "" {
+		return 1, nil
+	}
+
+	scale := 1.0
+	rest := unit
+	switch strings.ToLower(unit[:1]) {
+	case "k":
+		scale, rest = 1e-3, unit[1:]
+	case "m":
+		scale, rest = 1, unit[1:]
+	case "g":
+		scale, rest = 1e3, unit[1:]
+	case "t":
+		scale, rest = 1e6, unit[1:]
+	default:
+		scale = 1e-6
+	}
+
+	switch rest {
+	case "bps", "b/s", "bit/s", "bits/s", "bit":
+	case "Bps", "B/s", "byte/s", "bytes/s":
+		scale *= 8
+	default:
+		return 0, &Error{msg: "Unknown speed unit '" + unit + "'"}
+	}
+
+	return scale, nil
+}
+
+// FastResults decodes the finished fast tests in the job, keyed by location.
+func (j Job) FastResults() (map[string]FastResult, error) {
+	results := map[string]FastResult{}
+	for city, tests := range j.Details.Done {
+		summary, ok := tests["fast"]
+		if !ok || summary == nil {
+			continue
+		}
+
+		var result FastResult
+		if err := decodeSummary(summary, &result); err != nil {
+			return nil, &Error{msg: "Invalid fast result from " + city + ": " + err.Error()}
+		}
+		results[city] = result
+	}
+
+	return results, nil
+}
+
+// ThroughputRank is one location's download speed, compared to the median
+// across the job. Slow means it's below the threshold it was ranked with.
+type ThroughputRank struct {
+	Location string
+	Mbps     float64
+	Percent  float64
+	Slow     bool
+}
+
+// RankThroughput ranks every location with a finished fast test, fastest
+// first. Locations below threshold percent of the median are flagged as
+// slow, e.g. 50 flags anything under half the median speed.
+func (j Job) RankThroughput(threshold float64) ([]ThroughputRank, error) {
+	results, err := j.FastResults()
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make([]ThroughputRank, 0, len(results))
+	speeds := make([]float64, 0, len(results))
+	for city, result := range results {
+		mbps, err := result.Mbps()
+		if err != nil {
+			return nil, &Error{msg: "Invalid fast result from " + city + ": " + err.Error()}
+		}
+		ranks = append(ranks, ThroughputRank{Location: city, Mbps: mbps})
+		speeds = append(speeds, mbps)
+	}
+
+	middle := Median(speeds)
+	for i := range ranks {
+		if middle > 0 {
+			ranks[i].Percent = ranks[i].Mbps * 100 / middle
+		}
+		ranks[i].Slow = ranks[i].Percent < threshold
+	}
+
+	sort.Slice(ranks, func(a, b int) bool {
+		if ranks[a].Mbps != ranks[b].Mbps {
+			return ranks[a].Mbps > ranks[b].Mbps
+		}
+		return ranks[a].Location < ranks[b].Location
+	})
+
+	return ranks, nil
+}
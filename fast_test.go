@@ -0,0 +1,81 @@
+package gowup
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type FastTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestFast(t *testing.T) {
+	suite.Run(t, new(FastTest))
+}
+
+func (f *FastTest) SetupTest() {
+	f.job = Job{Details: JobDetails{Done: JobDetail{
+		"denver": {"fast": map[string]interface{}{"speed": 100, "unit": "Mbps"}},
+		"riga":   {"fast": map[string]interface{}{"speed": 0.2, "unit": "Gbps"}},
+		"tokyo":  {"fast": map[string]interface{}{"speed": 5, "unit": "MB/s"}},
+		"sydney": {"fast": map[string]interface{}{"bytes": 2500000, "duration": 1000}},
+		"paris":  {"ping": map[string]interface{}{"avg": 10}},
+	}}}
+}
+
+func (f *FastTest) TestMbps() {
+	for _, c := range []struct {
+		result FastResult
+		mbps   float64
+	}{
+		{FastResult{Speed: 45}, 45},
+		{FastResult{Speed: 45, Unit: "Mbps"}, 45},
+		{FastResult{Speed: 1.5, Unit: "Gbps"}, 1500},
+		{FastResult{Speed: 500, Unit: "kbit/s"}, 0.5},
+		{FastResult{Speed: 10, Unit: "MB/s"}, 80},
+		{FastResult{Speed: 1000000, Unit: "bps"}, 1},
+		{FastResult{Bytes: 12500000, Duration: 2000}, 50},
+	} {
+		mbps, err := c.result.Mbps()
+		f.NoError(err)
+		f.InDelta(c.mbps, mbps, 0.0001, "should convert %+v", c.result)
+	}
+
+	_, err := FastResult{Speed: 10, Unit: "furlongs"}.Mbps()
+	f.Error(err, "should reject unknown units")
+}
+
+func (f *FastTest) TestFastResults() {
+	results, err := f.job.FastResults()
+	f.NoError(err)
+	f.Equal(4, len(results), "should only decode locations with fast tests")
+	f.Equal(FastResult{Speed: 5, Unit: "MB/s"}, results["tokyo"])
+
+	f.job.Details.Done["denver"]["fast"] = "nonsense"
+	_, err = f.job.FastResults()
+	f.Error(err)
+	f.Contains(err.Error(), "denver")
+}
+
+func (f *FastTest) TestRankThroughput() {
+	ranks, err := f.job.RankThroughput(50)
+	f.NoError(err)
+
+	// speeds are 200, 100, 40 and 20, so the median is 70
+	f.Equal([]string{"riga", "denver", "tokyo", "sydney"}, []string{ranks[0].Location, ranks[1].Location, ranks[2].Location, ranks[3].Location}, "should rank fastest first")
+	f.InDelta(200.0/70*100, ranks[0].Percent, 0.0001)
+	f.False(ranks[2].Slow, "should not flag locations above the threshold")
+	f.True(ranks[3].Slow, "should flag locations below the threshold")
+	f.InDelta(20, ranks[3].Mbps, 0.0001)
+
+	f.job.Details.Done["denver"]["fast"] = map[string]interface{}{"speed": 1, "unit": "parsecs"}
+	_, err = f.job.RankThroughput(50)
+	f.Error(err)
+}
+
+func (f *FastTest) TestEmpty() {
+	ranks, err := Job{}.RankThroughput(50)
+	f.NoError(err)
+	f.Empty(ranks)
+}
@@ -0,0 +1,67 @@
+package gowup
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Hooks are called around every API request. Either one can be nil.
+type Hooks struct {
+	OnRequest  func(RequestInfo)
+	OnResponse func(ResponseInfo)
+}
+
+// RequestInfo describes a request that's about to go out. Header has the
+// auth header masked. The client doesn't retry yet, so Attempt is always 1.
+type RequestInfo struct {
+	Method   string
+	Endpoint string
+	Url      string
+	Header   http.Header
+	Attempt  int
+}
+
+// ResponseInfo describes how a request went. Status and Size are 0 if the
+// request failed before there was a response; Err says why.
+type ResponseInfo struct {
+	RequestInfo
+	Status  int
+	Size    int
+	Latency time.Duration
+	Err     error
+}
+
+// SlogHooks logs every request at debug level and every response at info
+// level, or warn if it failed.
+func SlogHooks(logger *slog.Logger) *Hooks {
+	return &Hooks{
+		OnRequest: func(info RequestInfo) {
+			logger.Debug("wup request",
+				"method", info.Method,
+				"endpoint", info.Endpoint,
+				"attempt", info.Attempt,
+			)
+		},
+		OnResponse: func(info ResponseInfo) {
+			attrs := []any{
+				"method", info.Method,
+				"endpoint", info.Endpoint,
+				"attempt", info.Attempt,
+				"status", info.Status,
+				"latency", info.Latency,
+				"size", info.Size,
+			}
+
+			if info.Err != nil {
+				logger.Warn("wup response", append(attrs, "error", info.Err.Error())...)
+				return
+			}
+			if info.Status >= 400 {
+				logger.Warn("wup response", attrs...)
+				return
+			}
+			logger.Info("wup response", attrs...)
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package gowup
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/suite"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type HooksTest struct {
+	suite.Suite
+	api       WIU
+	requests  []RequestInfo
+	responses []ResponseInfo
+}
+
+func TestHooks(t *testing.T) {
+	suite.Run(t, new(HooksTest))
+}
+
+func (h *HooksTest) SetupTest() {
+	h.requests, h.responses = nil, nil
+	h.api = WIU{Client: "herp", Token: "derp", Hooks: &Hooks{
+		OnRequest:  func(info RequestInfo) { h.requests = append(h.requests, info) },
+		OnResponse: func(info ResponseInfo) { h.responses = append(h.responses, info) },
+	}}
+}
+
+func (h *HooksTest) TestHooks() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobID": "534419e98c3dcffa6170aeae"}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	_, err := h.api.Submit(&JobRequest{})
+	h.NoError(err, "should not return an error")
+
+	h.Equal(1, len(h.requests), "should call OnRequest once")
+	h.Equal("POST", h.requests[0].Method)
+	h.Equal("jobs", h.requests[0].Endpoint)
+	h.Equal(server.URL+"/jobs", h.requests[0].Url)
+	h.Equal(1, h.requests[0].Attempt)
+	h.Equal("****", h.requests[0].Header.Get("Auth"), "should mask the auth header")
+
+	h.Equal(1, len(h.responses), "should call OnResponse once")
+	h.Equal(200, h.responses[0].Status)
+	h.Equal(37, h.responses[0].Size, "should measure the body")
+	h.True(h.responses[0].Latency > 0, "should time the request")
+	h.NoError(h.responses[0].Err)
+}
+
+func (h *HooksTest) TestFailedRequest() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	apiEntryPoint = server.URL
+	server.Close()
+
+	_, err := h.api.Jobs()
+	h.Error(err)
+	h.Equal(1, len(h.responses))
+	h.Equal(0, h.responses[0].Status, "should not have a status without a response")
+	h.Error(h.responses[0].Err, "should pass the error along")
+}
+
+func (h *HooksTest) TestNoHooks() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	_, err := WIU{Hooks: &Hooks{}}.Jobs()
+	h.NoError(err, "should cope with empty hooks")
+}
+
+func (h *HooksTest) TestSlogHooks() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", 503)
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	var logged bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	WIU{Client: "herp", Token: "derp", Hooks: SlogHooks(logger)}.Jobs()
+
+	h.Contains(logged.String(), `level=DEBUG msg="wup request" method=GET endpoint=jobs attempt=1`)
+	h.Contains(logged.String(), `level=WARN msg="wup response" method=GET endpoint=jobs attempt=1 status=503`)
+	h.NotContains(logged.String(), "derp", "should not log the token")
+}
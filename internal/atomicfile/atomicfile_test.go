@@ -0,0 +1,47 @@
+package atomicfile
+
+import (
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type AtomicFileTest struct {
+	suite.Suite
+	dir string
+}
+
+func TestAtomicFile(t *testing.T) {
+	suite.Run(t, new(AtomicFileTest))
+}
+
+func (a *AtomicFileTest) SetupTest() {
+	a.dir = a.T().TempDir()
+}
+
+func (a *AtomicFileTest) TestWrite() {
+	path := filepath.Join(a.dir, "state.json")
+
+	a.NoError(Write(path, []byte("one"), 0600))
+	a.NoError(Write(path, []byte("two"), 0600))
+
+	raw, err := ioutil.ReadFile(path)
+	a.NoError(err)
+	a.Equal("two", string(raw), "should replace the file")
+
+	info, err := os.Stat(path)
+	a.NoError(err)
+	a.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	entries, _ := ioutil.ReadDir(a.dir)
+	a.Equal(1, len(entries), "should not leave temp files behind")
+}
+
+func (a *AtomicFileTest) TestMissingDir() {
+	a.Error(Write(filepath.Join(a.dir, "nope", "state.json"), []byte("one"), 0600))
+
+	entries, _ := ioutil.ReadDir(a.dir)
+	a.Empty(entries)
+}
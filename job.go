@@ -3,15 +3,55 @@ package gowup
 import (
 	"encoding/json"
 	"net/url"
+	"sort"
 	"time"
 )
 
 type Job struct {
+	Id      JobID      `json:"-"`
 	Summary JobSummary `json:"request"`
 	Details JobDetails `json:"response"`
 }
 
+// JobStatus is where a job is at, worked out from its results and expiry.
+type JobStatus string
+
+const (
+	// Pending jobs haven't sent back any results yet.
+	Pending JobStatus = "pending"
+	// Running jobs have tests in progress.
+	Running JobStatus = "running"
+	// Complete jobs finished every test without errors.
+	Complete JobStatus = "complete"
+	// PartiallyFailed jobs finished, but some tests errored.
+	PartiallyFailed JobStatus = "partially_failed"
+	// Expired jobs are past their expiry time, so the results are going or
+	// gone.
+	Expired JobStatus = "expired"
+)
+
+// Counts of tests in each state.
+type Counts struct {
+	Done    int
+	NotDone int
+	Error   int
+}
+
+// Total is the number of tests counted.
+func (c Counts) Total() int {
+	return c.Done + c.NotDone + c.Error
+}
+
+// Progress counts the tests in a job by state, overall and broken down by
+// location and by test.
+type Progress struct {
+	Counts
+	Locations map[string]Counts
+	Tests     map[string]Counts
+}
+
 type JobSummary struct {
+	Id         JobID     `json:"id"`
 	Url        Url       `json:"url"`
 	Ip         string    `json:"ip"`
 	StartTime  Time      `json:"start_time"`
@@ -34,6 +74,59 @@ type JobDetails struct {
 	Done    JobDetail `json:"complete"`
 	NotDone JobDetail `json:"in_progress"`
 	Error   JobDetail `json:"error"`
+	Raw     JobDetail `json:"-"`
+}
+
+// JobDetail only keeps the summaries, so the raw output of each test gets
+// picked out separately into Raw. Tests that are running or errored don't
+// always have a summary, but they still need to show up, so they're kept with
+// a nil one.
+func (d *JobDetails) UnmarshalJSON(data []byte) error {
+	type details JobDetails
+	var decoded details
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*d = JobDetails(decoded)
+
+	var states map[string]interface{}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	d.Raw = JobDetail{}
+	listed := map[string]JobDetail{"in_progress": d.NotDone, "error": d.Error}
+	for name, state := range states {
+		cities, ok := state.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for city, tests := range cities {
+			tests, ok := tests.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for test, details := range tests {
+				details, ok := details.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if detail := listed[name]; detail != nil {
+					if _, ok := detail[city][test]; !ok && detail[city] != nil {
+						detail[city][test] = nil
+					}
+				}
+				if raw, ok := details["raw"]; ok {
+					if d.Raw[city] == nil {
+						d.Raw[city] = map[string]interface{}{}
+					}
+					d.Raw[city][test] = raw
+				}
+			}
+		}
+	}
+
+	return nil
 }
 
 type JobDetail map[string]map[string]interface{}
@@ -121,3 +214,77 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Status is the job's status right now.
+func (j Job) Status() JobStatus {
+	return j.StatusAt(time.Now())
+}
+
+// StatusAt is the job's status at the given time. Only expiry depends on
+// the time.
+func (j Job) StatusAt(now time.Time) JobStatus {
+	progress := j.Progress()
+
+	switch {
+	case !j.Summary.ExpireTime.IsZero() && j.Summary.ExpireTime.Before(now):
+		return Expired
+	case progress.Total() == 0:
+		return Pending
+	case progress.NotDone > 0:
+		return Running
+	case progress.Error > 0:
+		return PartiallyFailed
+	}
+
+	return Complete
+}
+
+// Progress counts the tests in each state.
+func (j Job) Progress() Progress {
+	progress := Progress{Locations: map[string]Counts{}, Tests: map[string]Counts{}}
+
+	count := func(detail JobDetail, bump func(*Counts)) {
+		for city, tests := range detail {
+			for test := range tests {
+				bump(&progress.Counts)
+
+				location := progress.Locations[city]
+				bump(&location)
+				progress.Locations[city] = location
+
+				byTest := progress.Tests[test]
+				bump(&byTest)
+				progress.Tests[test] = byTest
+			}
+		}
+	}
+
+	count(j.Details.Done, func(c *Counts) { c.Done++ })
+	count(j.Details.NotDone, func(c *Counts) { c.NotDone++ })
+	count(j.Details.Error, func(c *Counts) { c.Error++ })
+
+	return progress
+}
+
+// Finished is true once the job has results and nothing is left in progress.
+func (j Job) Finished() bool {
+	return len(j.Details.NotDone) == 0 && len(j.Details.Done)+len(j.Details.Error) > 0
+}
+
+// locationNames lists every location in the job, finished or not, sorted.
+func (j Job) locationNames() []string {
+	seen := map[string]bool{}
+	for _, detail := range []JobDetail{j.Details.Done, j.Details.NotDone, j.Details.Error} {
+		for city := range detail {
+			seen[city] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for city := range seen {
+		names = append(names, city)
+	}
+	sort.Strings(names)
+
+	return names
+}
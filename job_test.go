@@ -123,4 +123,89 @@ func (j *JobSummaryTest) TestJobDetailUnmarshaler() {
 	data = []byte(`[]`)
 	json.Unmarshal(data, &detail)
 	j.Equal(JobDetail{}, detail)
+
+	data = []byte(`{"riga": {"http": {}}}`)
+	json.Unmarshal(data, &detail)
+	_, ok := detail["riga"]["http"]
+	j.False(ok, "should skip tests without summaries")
+}
+
+func (j *JobSummaryTest) TestFinished() {
+	j.False(Job{}.Finished(), "should not be finished without results")
+	j.False(Job{Details: JobDetails{
+		Done:    JobDetail{"denver": {}},
+		NotDone: JobDetail{"sydney": {}},
+	}}.Finished(), "should not be finished with tests in progress")
+	j.True(Job{Details: JobDetails{Error: JobDetail{"sydney": {}}}}.Finished(), "should be finished with only errors")
+}
+
+func (j *JobSummaryTest) TestJobDetailsRaw() {
+	data := []byte(`{
+	    "complete": {
+	        "denver": {
+	            "ping": {"raw": "PING google.com", "summary": {"avg": 1}}
+	        }
+	    },
+	    "error": {
+	        "tokyo": {
+	            "http": {"raw": "connection refused", "summary": null}
+	        }
+	    },
+	    "in_progress": []
+	}`)
+
+	details := JobDetails{}
+	j.NoError(json.Unmarshal(data, &details))
+	j.Equal("PING google.com", details.Raw["denver"]["ping"], "should keep raw output")
+	j.Equal("connection refused", details.Raw["tokyo"]["http"], "should keep raw output for errors")
+	j.Equal(map[string]interface{}{"avg": float64(1)}, details.Done["denver"]["ping"], "should still decode summaries")
+
+	data = []byte(`{
+	    "complete": {"denver": {"ping": {"raw": "PING google.com"}}},
+	    "error": {"tokyo": {"http": {"raw": "connection refused"}}},
+	    "in_progress": {"riga": {"http": {}}}
+	}`)
+	details = JobDetails{}
+	j.NoError(json.Unmarshal(data, &details))
+	_, ok := details.Done["denver"]["ping"]
+	j.False(ok, "should skip finished tests without summaries")
+	_, ok = details.Error["tokyo"]["http"]
+	j.True(ok, "should keep errored tests without summaries")
+	_, ok = details.NotDone["riga"]["http"]
+	j.True(ok, "should keep running tests without summaries")
+}
+
+func (j *JobSummaryTest) TestStatus() {
+	now := time.Unix(1404053589, 0)
+	expiry := Time{Time: now.Add(time.Hour)}
+
+	job := Job{Summary: JobSummary{ExpireTime: expiry}}
+	j.Equal(Pending, job.StatusAt(now), "should be pending without results")
+
+	job.Details.NotDone = JobDetail{"riga": {"ping": nil}}
+	job.Details.Done = JobDetail{"denver": {"ping": nil}}
+	j.Equal(Running, job.StatusAt(now), "should be running with tests in progress")
+
+	job.Details.NotDone = nil
+	j.Equal(Complete, job.StatusAt(now), "should be complete when everything's done")
+
+	job.Details.Error = JobDetail{"riga": {"ping": nil}}
+	j.Equal(PartiallyFailed, job.StatusAt(now), "should be partially failed with errors")
+
+	j.Equal(Expired, job.StatusAt(now.Add(2*time.Hour)), "should be expired after the expiry time")
+	j.Equal(PartiallyFailed, Job{Details: job.Details}.StatusAt(now.Add(2*time.Hour)), "should not expire without an expiry time")
+}
+
+func (j *JobSummaryTest) TestProgress() {
+	job := Job{Details: JobDetails{
+		Done:    JobDetail{"denver": {"ping": nil, "http": nil}, "riga": {"ping": nil}},
+		NotDone: JobDetail{"riga": {"http": nil}},
+		Error:   JobDetail{"tokyo": {"ping": nil, "http": nil}},
+	}}
+
+	progress := job.Progress()
+	j.Equal(Counts{Done: 3, NotDone: 1, Error: 2}, progress.Counts, "should count every test")
+	j.Equal(6, progress.Total())
+	j.Equal(Counts{Done: 1, NotDone: 1}, progress.Locations["riga"], "should count by location")
+	j.Equal(Counts{Done: 2, Error: 1}, progress.Tests["ping"], "should count by test")
 }
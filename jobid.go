@@ -0,0 +1,87 @@
+package gowup
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// jobIDLength is how many hex characters are in a job ID, e.g.
+// 534419e98c3dcffa6170aeae.
+const jobIDLength = 24
+
+// JobID identifies a job. Use ParseJobID to get one from user input; the
+// API calls check them again anyway, so a bad conversion can't get a request
+// out the door. The zero value is "no ID", and survives a round trip through
+// JSON so empty summaries still encode.
+type JobID string
+
+// ParseJobID checks that id looks like a job ID: 24 hex characters. Upper
+// case is fine, and comes back lower case.
+func ParseJobID(id string) (JobID, error) {
+	normalized := strings.ToLower(strings.TrimSpace(id))
+
+	if len(normalized) != jobIDLength {
+		return "", &Error{msg: "Invalid job ID '" + id + "': expected 24 hex characters"}
+	}
+	if _, err := hex.DecodeString(normalized); err != nil {
+		return "", &Error{msg: "Invalid job ID '" + id + "': " + err.Error()}
+	}
+
+	return JobID(normalized), nil
+}
+
+func (id JobID) String() string {
+	return string(id)
+}
+
+// Validate checks an ID that didn't come from ParseJobID.
+func (id JobID) Validate() error {
+	parsed, err := ParseJobID(string(id))
+	if err == nil && parsed != id {
+		err = &Error{msg: "Invalid job ID '" + string(id) + "': expected lower case"}
+	}
+	return err
+}
+
+func (id JobID) MarshalText() ([]byte, error) {
+	if id == "" {
+		return []byte{}, nil
+	}
+	if err := id.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(id), nil
+}
+
+func (id *JobID) UnmarshalText(raw []byte) error {
+	if len(raw) == 0 {
+		*id = ""
+		return nil
+	}
+
+	parsed, err := ParseJobID(string(raw))
+	if err != nil {
+		return err
+	}
+
+	*id = parsed
+	return nil
+}
+
+func (id JobID) MarshalJSON() ([]byte, error) {
+	text, err := id.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+func (id *JobID) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+
+	return id.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,61 @@
+package gowup
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type JobIDTest struct {
+	suite.Suite
+}
+
+func TestJobID(t *testing.T) {
+	suite.Run(t, new(JobIDTest))
+}
+
+func (j *JobIDTest) TestParse() {
+	id, err := ParseJobID("534419e98c3dcffa6170aeae")
+	j.NoError(err)
+	j.Equal(JobID("534419e98c3dcffa6170aeae"), id)
+
+	id, err = ParseJobID(" 534419E98C3DCFFA6170AEAE\n")
+	j.NoError(err, "should tidy up case and whitespace")
+	j.Equal(JobID("534419e98c3dcffa6170aeae"), id)
+
+	for _, bad := range []string{"", "aa", "534419e98c3dcffa6170aeae00", "534419e98c3dcffa6170aeaz"} {
+		_, err := ParseJobID(bad)
+		j.Error(err, "should reject '"+bad+"'")
+	}
+}
+
+func (j *JobIDTest) TestValidate() {
+	j.NoError(JobID("534419e98c3dcffa6170aeae").Validate())
+	j.Error(JobID("534419E98C3DCFFA6170AEAE").Validate(), "should want lower case")
+	j.Error(JobID("").Validate())
+}
+
+func (j *JobIDTest) TestJson() {
+	var job JobSummary
+	j.NoError(json.Unmarshal([]byte(`{"id": "534419E98C3DCFFA6170AEAE"}`), &job))
+	j.Equal(JobID("534419e98c3dcffa6170aeae"), job.Id, "should parse ids")
+	j.Error(json.Unmarshal([]byte(`{"id": "herpderp"}`), &job), "should reject bad ids")
+
+	var jobs map[JobID]JobSummary
+	j.NoError(json.Unmarshal([]byte(`{"534419e98c3dcffa6170aeae": {}}`), &jobs))
+	j.Contains(jobs, JobID("534419e98c3dcffa6170aeae"), "should parse map keys")
+	j.Error(json.Unmarshal([]byte(`{"herpderp": {}}`), &jobs), "should reject bad map keys")
+
+	raw, err := json.Marshal(map[JobID]JobID{"534419e98c3dcffa6170aeae": "534419e98c3dcffa6170aeaf"})
+	j.NoError(err)
+	j.Equal(`{"534419e98c3dcffa6170aeae":"534419e98c3dcffa6170aeaf"}`, string(raw))
+
+	_, err = json.Marshal(JobID("herpderp"))
+	j.Error(err, "should not write bad ids")
+
+	var empty struct{ Id JobID }
+	raw, err = json.Marshal(empty)
+	j.NoError(err, "should write empty ids")
+	j.Equal(`{"Id":""}`, string(raw))
+	j.NoError(json.Unmarshal(raw, &empty), "should read empty ids")
+}
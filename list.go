@@ -0,0 +1,185 @@
+package gowup
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobFilter narrows down a job listing. Empty fields match everything. Url
+// matches anywhere in the job's URL; Test and Location match if any of the
+// job's services ran that test or ran from that location; Since and Until
+// bound the start time.
+type JobFilter struct {
+	Url      string
+	Test     string
+	Location string
+	Since    time.Time
+	Until    time.Time
+}
+
+// Match checks one job against the filter.
+func (f JobFilter) Match(job JobSummary) bool {
+	if f.Url != "" && (job.Url.URL == nil || !strings.Contains(job.Url.String(), f.Url)) {
+		return false
+	}
+	if !f.Since.IsZero() && job.StartTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && job.StartTime.After(f.Until) {
+		return false
+	}
+
+	if f.Test == "" && f.Location == "" {
+		return true
+	}
+	for _, service := range job.Services {
+		if f.Location != "" && service.Server != f.Location {
+			continue
+		}
+		if f.Test != "" && !contains(service.Tests, f.Test) {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// JobIterator walks through your jobs a page at a time, newest first,
+// fetching the next page only when it runs out:
+//
+//	jobs := api.ListJobs(gowup.JobFilter{Test: "ping"})
+//	for jobs.Next() {
+//	    fmt.Println(jobs.Job().Id)
+//	}
+//	if err := jobs.Err(); err != nil {
+//	    ...
+//	}
+type JobIterator struct {
+	// PageSize is how many jobs to ask for at a time.
+	PageSize int
+
+	api     WIU
+	filter  JobFilter
+	page    int
+	buffer  []JobSummary
+	current JobSummary
+	seen    map[JobID]bool
+	done    bool
+	err     error
+}
+
+// ListJobs lists your jobs that match filter, newest first.
+func (api WIU) ListJobs(filter JobFilter) *JobIterator {
+	return &JobIterator{PageSize: 50, api: api, filter: filter, seen: map[JobID]bool{}}
+}
+
+// Next moves to the next matching job. It's false when there are no more,
+// or something went wrong; check Err to tell which.
+func (it *JobIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+// Job is the job Next moved to.
+func (it *JobIterator) Job() JobSummary {
+	return it.current
+}
+
+// Err is the error that stopped the iterator, if any.
+func (it *JobIterator) Err() error {
+	return it.err
+}
+
+// All collects the rest of the jobs into a slice.
+func (it *JobIterator) All() ([]JobSummary, error) {
+	var jobs []JobSummary
+	for it.Next() {
+		jobs = append(jobs, it.Job())
+	}
+	return jobs, it.Err()
+}
+
+// fetch loads the next page. A short page, or one with nothing new in it,
+// is the last one; the second check keeps us from going round forever if
+// the API ignores the page parameters.
+func (it *JobIterator) fetch() {
+	it.page++
+	jobs, listed, err := it.api.jobsPage(it.page, it.PageSize)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	fresh := 0
+	for _, job := range jobs {
+		if it.seen[job.Id] {
+			continue
+		}
+		it.seen[job.Id] = true
+		fresh++
+
+		if it.filter.Match(job) {
+			it.buffer = append(it.buffer, job)
+		}
+	}
+
+	if fresh == 0 || listed < it.PageSize {
+		it.done = true
+	}
+}
+
+// jobsPage gets one page of jobs, sorted newest first. Like Jobs, it skips
+// anything listed under an ID that isn't a job ID; listed counts everything
+// on the page, so a skipped entry doesn't make the page look short.
+func (api WIU) jobsPage(page, size int) (jobs []JobSummary, listed int, err error) {
+	ctx, op := api.startOperation(context.Background(), "ListJobs")
+	op.set("wup.page", page)
+	defer func() {
+		op.set("wup.job_count", len(jobs))
+		op.finish(err)
+	}()
+
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(size))
+
+	response, err := api.get(ctx, "jobs?"+query.Encode())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var byId map[string]JobSummary
+	if err := api.parse(response, &byId); err != nil {
+		return nil, 0, err
+	}
+
+	for key, job := range byId {
+		id, err := ParseJobID(key)
+		if err != nil {
+			continue
+		}
+		job.Id = id
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(a, b int) bool {
+		if !jobs[a].StartTime.Equal(jobs[b].StartTime.Time) {
+			return jobs[a].StartTime.After(jobs[b].StartTime.Time)
+		}
+		return jobs[a].Id < jobs[b].Id
+	})
+
+	return jobs, len(byId), nil
+}
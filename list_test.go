@@ -0,0 +1,157 @@
+package gowup
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type ListTest struct {
+	suite.Suite
+	api   WIU
+	pages []string
+}
+
+func TestList(t *testing.T) {
+	suite.Run(t, new(ListTest))
+}
+
+func (l *ListTest) SetupTest() {
+	l.api = WIU{Client: "herp", Token: "derp"}
+	l.pages = nil
+}
+
+// serve hands out count jobs, one started every minute, newest first
+func (l *ListTest) serve(count int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.pages = append(l.pages, r.URL.RawQuery)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+
+		jobs := map[string]interface{}{}
+		for i := (page - 1) * size; i < page*size && i < count; i++ {
+			test, city := "ping", "denver"
+			if i%2 == 1 {
+				test, city = "http", "riga"
+			}
+			jobs[fmt.Sprintf("%024x", i)] = map[string]interface{}{
+				"url":        fmt.Sprintf("https://example.com/%d", i),
+				"start_time": 1404053589 - i*60,
+				"services":   []interface{}{map[string]interface{}{"server": city, "checks": []string{test}}},
+			}
+		}
+
+		data, _ := json.Marshal(jobs)
+		w.Write(data)
+	}))
+	apiEntryPoint = server.URL
+	return server
+}
+
+func (l *ListTest) TestPaging() {
+	defer l.serve(5).Close()
+
+	it := l.api.ListJobs(JobFilter{})
+	it.PageSize = 2
+
+	jobs, err := it.All()
+	l.NoError(err, "should not return an error")
+	l.Equal(5, len(jobs), "should get every job")
+	l.Equal(JobID("000000000000000000000000"), jobs[0].Id, "should fill in ids")
+	l.Equal(JobID("000000000000000000000004"), jobs[4].Id, "should keep jobs in order")
+	l.Equal([]string{"page=1&per_page=2", "page=2&per_page=2", "page=3&per_page=2"}, l.pages, "should stop after a short page")
+}
+
+func (l *ListTest) TestLazy() {
+	defer l.serve(10).Close()
+
+	it := l.api.ListJobs(JobFilter{})
+	it.PageSize = 3
+	it.Next()
+	it.Next()
+
+	l.Equal(1, len(l.pages), "should not fetch pages until it needs them")
+}
+
+func (l *ListTest) TestIgnoredPaging() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.pages = append(l.pages, r.URL.RawQuery)
+		w.Write([]byte(`{"aaaaaaaaaaaaaaaaaaaaaaaa": {"url": "https://google.com"}, "bbbbbbbbbbbbbbbbbbbbbbbb": {"url": "https://google.com"}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	it := l.api.ListJobs(JobFilter{})
+	it.PageSize = 2
+
+	jobs, err := it.All()
+	l.NoError(err)
+	l.Equal(2, len(jobs), "should not repeat jobs")
+	l.Equal(2, len(l.pages), "should stop when a page has nothing new")
+}
+
+func (l *ListTest) TestBadIds() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.pages = append(l.pages, r.URL.RawQuery)
+		if r.URL.Query().Get("page") != "1" {
+			w.Write([]byte(`{"bbbbbbbbbbbbbbbbbbbbbbbb": {"url": "https://example.com"}}`))
+			return
+		}
+		w.Write([]byte(`{"aaaaaaaaaaaaaaaaaaaaaaaa": {"url": "https://google.com"}, "herpderp": {"url": "https://google.com"}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	it := l.api.ListJobs(JobFilter{})
+	it.PageSize = 2
+
+	jobs, err := it.All()
+	l.NoError(err, "should not fail the page")
+	l.Equal([]JobID{"aaaaaaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbbbbbb"}, []JobID{jobs[0].Id, jobs[1].Id}, "should skip ids that aren't job ids")
+	l.Equal(2, len(l.pages), "should not treat a page with a skipped id as the last")
+}
+
+func (l *ListTest) TestFilters() {
+	defer l.serve(6).Close()
+
+	jobs, _ := l.api.ListJobs(JobFilter{Test: "http"}).All()
+	l.Equal(3, len(jobs), "should filter by test")
+
+	jobs, _ = l.api.ListJobs(JobFilter{Location: "denver", Test: "http"}).All()
+	l.Equal(0, len(jobs), "should match test and location on the same service")
+
+	jobs, _ = l.api.ListJobs(JobFilter{Url: "example.com/3"}).All()
+	l.Equal(1, len(jobs), "should filter by url")
+
+	jobs, _ = l.api.ListJobs(JobFilter{
+		Since: time.Unix(1404053589-3*60, 0),
+		Until: time.Unix(1404053589-1*60, 0),
+	}).All()
+	l.Equal([]JobID{"000000000000000000000001", "000000000000000000000002", "000000000000000000000003"}, []JobID{jobs[0].Id, jobs[1].Id, jobs[2].Id}, "should filter by start time")
+}
+
+func (l *ListTest) TestError() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	apiEntryPoint = server.URL
+	server.Close()
+
+	it := l.api.ListJobs(JobFilter{})
+	l.False(it.Next())
+	l.Error(it.Err(), "should report the error")
+}
+
+func (l *ListTest) TestFilterMatch() {
+	u, _ := url.Parse("https://google.com")
+	job := JobSummary{Url: Url{URL: u}}
+
+	l.True(JobFilter{}.Match(job), "should match anything without a filter")
+	l.False(JobFilter{Test: "ping"}.Match(job), "should not match jobs without services")
+	l.False(JobFilter{Url: "google"}.Match(JobSummary{}), "should not match jobs without urls")
+}
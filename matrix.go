@@ -0,0 +1,290 @@
+package gowup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Reach is whether a target could be reached from a location.
+type Reach string
+
+const (
+	// ReachUp means at least one test got through: a ping came back, or an
+	// HTTP request got a response below 400.
+	ReachUp Reach = "up"
+	// ReachDown means the tests finished and none of them got through.
+	ReachDown Reach = "down"
+	// ReachError means every test errored out, so there's no telling.
+	ReachError Reach = "error"
+	// ReachPending means tests were still running when the wait ended.
+	ReachPending Reach = "pending"
+	// ReachUnknown means there are no results at all, e.g. the job couldn't
+	// be submitted.
+	ReachUnknown Reach = "unknown"
+)
+
+// Cell is one target from one location. Latency is the average ping, or the
+// total HTTP time if there's no ping; Status is the final HTTP status. Reason
+// explains anything but ReachUp.
+type Cell struct {
+	Reach   Reach   `json:"reach"`
+	Latency float64 `json:"latency_ms,omitempty"`
+	Loss    float64 `json:"loss,omitempty"`
+	Status  int     `json:"status,omitempty"`
+	Reason  string  `json:"reason,omitempty"`
+}
+
+// Matrix is a targets x locations grid of reachability, for working out
+// what can be reached from where at a glance. Jobs has the job behind each
+// target, for digging deeper.
+type Matrix struct {
+	Targets   []string                   `json:"targets"`
+	Locations []string                   `json:"locations"`
+	Cells     map[string]map[string]Cell `json:"cells"`
+	Jobs      map[string]JobID           `json:"jobs"`
+}
+
+// MatrixBuilder submits a job per target from every location, waits for
+// them all, and fills in a Matrix. Tests default to ping and http, and Poll
+// to five seconds. Give ctx a deadline: whatever's still running when it's
+// done is cancelled and shows up as pending.
+type MatrixBuilder struct {
+	Api       WIU
+	Targets   []string
+	Locations []string
+	Tests     []string
+	Poll      time.Duration
+}
+
+// Cell is one target from one location, ReachUnknown if it's not in the
+// matrix.
+func (m *Matrix) Cell(target, location string) Cell {
+	cell, ok := m.Cells[target][location]
+	if !ok {
+		return Cell{Reach: ReachUnknown}
+	}
+	return cell
+}
+
+// Build submits the jobs and waits for them. It only fails outright if no
+// job could be submitted; targets that fail on their own get ReachUnknown
+// cells with the error as the reason.
+func (b MatrixBuilder) Build(ctx context.Context) (*Matrix, error) {
+	if len(b.Targets) == 0 || len(b.Locations) == 0 {
+		return nil, &Error{msg: "A matrix needs targets and locations"}
+	}
+
+	tests, poll := b.Tests, b.Poll
+	if len(tests) == 0 {
+		tests = []string{"ping", "http"}
+	}
+	if poll <= 0 {
+		poll = 5 * time.Second
+	}
+
+	m := &Matrix{
+		Targets:   b.Targets,
+		Locations: b.Locations,
+		Cells:     map[string]map[string]Cell{},
+		Jobs:      map[string]JobID{},
+	}
+
+	// submit everything first so the jobs run side by side
+	failed := map[string]error{}
+	for _, target := range b.Targets {
+		id, err := b.Api.Submit(&JobRequest{Url: target, Tests: tests, Locations: b.Locations})
+		if err != nil {
+			failed[target] = err
+			continue
+		}
+		m.Jobs[target] = id
+	}
+	if len(failed) == len(b.Targets) {
+		return nil, failed[b.Targets[0]]
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for target, id := range m.Jobs {
+		wg.Add(1)
+		go func(target string, id JobID) {
+			defer wg.Done()
+
+			// anything still running at the deadline gets cancelled, but
+			// what finished still fills in its cells
+			job, err := b.Api.WaitOrCancel(ctx, id, poll)
+			row := map[string]Cell{}
+			for _, city := range b.Locations {
+				if job == nil {
+					row[city] = Cell{Reach: ReachUnknown, Reason: err.Error()}
+					continue
+				}
+				row[city] = reachFrom(*job, city, tests)
+			}
+
+			mu.Lock()
+			m.Cells[target] = row
+			mu.Unlock()
+		}(target, id)
+	}
+	wg.Wait()
+
+	for target, err := range failed {
+		row := map[string]Cell{}
+		for _, city := range b.Locations {
+			row[city] = Cell{Reach: ReachUnknown, Reason: err.Error()}
+		}
+		m.Cells[target] = row
+	}
+
+	return m, nil
+}
+
+// reachFrom works out the cell for one location in a job. One test getting
+// through is enough for ReachUp; otherwise anything still running makes it
+// ReachPending.
+func reachFrom(job Job, city string, tests []string) Cell {
+	cell := Cell{}
+	up, down, pending, errored := false, false, false, 0
+	pinged, httpTime := false, 0.0
+	var reasons []string
+
+	for _, test := range tests {
+		if _, ok := job.Details.Error[city][test]; ok {
+			errored++
+			reasons = append(reasons, test+" failed")
+			continue
+		}
+		if _, ok := job.Details.NotDone[city][test]; ok {
+			pending = true
+			continue
+		}
+
+		summary, ok := job.Details.Done[city][test]
+		if !ok || summary == nil {
+			continue
+		}
+
+		switch test {
+		case "ping":
+			var ping PingResult
+			if err := decodeSummary(summary, &ping); err != nil {
+				reasons = append(reasons, "ping: "+err.Error())
+				continue
+			}
+			pinged, cell.Latency, cell.Loss = true, ping.Avg, ping.Loss
+			if ping.Loss < 100 {
+				up = true
+			} else {
+				down = true
+				reasons = append(reasons, "no ping replies")
+			}
+		case "http":
+			var http HttpResult
+			if err := decodeSummary(summary, &http); err != nil {
+				reasons = append(reasons, "http: "+err.Error())
+				continue
+			}
+			final := http.Final()
+			if final == nil {
+				down = true
+				reasons = append(reasons, "no http response")
+				continue
+			}
+			cell.Status, httpTime = final.Status, http.Time()
+			if final.Status > 0 && final.Status < 400 {
+				up = true
+			} else {
+				down = true
+				reasons = append(reasons, "http "+strconv.Itoa(final.Status))
+			}
+		}
+	}
+	if !pinged {
+		cell.Latency = httpTime
+	}
+
+	switch {
+	case up:
+		cell.Reach = ReachUp
+		return cell
+	case pending:
+		cell.Reach = ReachPending
+	case down:
+		cell.Reach = ReachDown
+	case errored > 0:
+		cell.Reach = ReachError
+	default:
+		cell.Reach = ReachUnknown
+		reasons = append(reasons, "no results")
+	}
+	cell.Reason = strings.Join(reasons, "; ")
+
+	return cell
+}
+
+// Text writes the matrix as a table: a row per target, a column per
+// location, and the reach and latency in each cell.
+func (m *Matrix) Text(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TARGET\t"+strings.Join(m.Locations, "\t"))
+
+	for _, target := range m.Targets {
+		cells := make([]string, 0, len(m.Locations))
+		for _, city := range m.Locations {
+			cell := m.Cell(target, city)
+			text := string(cell.Reach)
+			if cell.Latency > 0 {
+				text += fmt.Sprintf(" %.1fms", cell.Latency)
+			}
+			cells = append(cells, text)
+		}
+		fmt.Fprintln(table, target+"\t"+strings.Join(cells, "\t"))
+	}
+
+	return table.Flush()
+}
+
+// Csv writes the matrix as CSV, one row per cell, so it pivots either way
+// in a spreadsheet.
+func (m *Matrix) Csv(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"target", "location", "reach", "latency_ms", "loss", "status", "reason"})
+
+	for _, target := range m.Targets {
+		for _, city := range m.Locations {
+			cell := m.Cell(target, city)
+			status := ""
+			if cell.Status != 0 {
+				status = strconv.Itoa(cell.Status)
+			}
+			writer.Write([]string{
+				target,
+				city,
+				string(cell.Reach),
+				strconv.FormatFloat(cell.Latency, 'f', -1, 64),
+				strconv.FormatFloat(cell.Loss, 'f', -1, 64),
+				status,
+				cell.Reason,
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Json writes the matrix as one indented JSON object.
+func (m *Matrix) Json(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
@@ -0,0 +1,152 @@
+package gowup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type MatrixTest struct {
+	suite.Suite
+	builder   MatrixBuilder
+	mu        sync.Mutex
+	cancelled []string
+}
+
+func TestMatrix(t *testing.T) {
+	suite.Run(t, new(MatrixTest))
+}
+
+// matrixJobs has a canned job for each target the server knows about
+var matrixJobs = map[string]struct {
+	id   string
+	body string
+}{
+	"https://up.example": {"534419e98c3dcffa6170aea1", `{"response": {"complete": {
+		"denver": {"ping": {"summary": {"avg": 12.5, "loss": 0}}, "http": {"summary": [{"status": 200, "time": 80}]}},
+		"riga": {"ping": {"summary": {"loss": 100}}, "http": {"summary": [{"status": 503, "time": 40}]}}
+	}, "error": [], "in_progress": []}}`},
+	"https://slow.example": {"534419e98c3dcffa6170aea2", `{"response": {"complete": [],
+		"error": {"denver": {"ping": {}, "http": {}}},
+		"in_progress": {"riga": {"ping": {}, "http": {}}}
+	}}`},
+}
+
+func (m *MatrixTest) SetupTest() {
+	m.cancelled = nil
+	m.builder = MatrixBuilder{
+		Api:       WIU{Client: "herp", Token: "derp"},
+		Targets:   []string{"https://up.example", "https://slow.example", "https://bad.example"},
+		Locations: []string{"denver", "riga"},
+		Poll:      time.Millisecond,
+	}
+}
+
+func (m *MatrixTest) server() *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cancel") {
+			m.mu.Lock()
+			m.cancelled = append(m.cancelled, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/cancel"))
+			m.mu.Unlock()
+			return
+		}
+
+		if r.Method == "POST" {
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+			if job, ok := matrixJobs[req["uri"].(string)]; ok {
+				w.Write([]byte(`{"jobID": "` + job.id + `"}`))
+				return
+			}
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		for _, job := range matrixJobs {
+			if strings.HasSuffix(r.URL.Path, job.id) {
+				w.Write([]byte(job.body))
+			}
+		}
+	}))
+	apiEntryPoint = server.URL
+	return server
+}
+
+func (m *MatrixTest) build() *Matrix {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	matrix, err := m.builder.Build(ctx)
+	m.NoError(err)
+	return matrix
+}
+
+func (m *MatrixTest) TestBuild() {
+	defer m.server().Close()
+	matrix := m.build()
+
+	m.Equal(Cell{Reach: ReachUp, Latency: 12.5, Status: 200}, matrix.Cell("https://up.example", "denver"))
+	m.Equal(Cell{Reach: ReachDown, Loss: 100, Status: 503, Reason: "no ping replies; http 503"}, matrix.Cell("https://up.example", "riga"))
+	m.Equal(ReachError, matrix.Cell("https://slow.example", "denver").Reach)
+	m.Equal(ReachPending, matrix.Cell("https://slow.example", "riga").Reach, "should show unfinished tests as pending")
+	m.Equal(Cell{Reach: ReachUnknown, Reason: "Submission failed"}, matrix.Cell("https://bad.example", "riga"), "should keep going when a submit fails")
+	m.Equal(JobID("534419e98c3dcffa6170aea1"), matrix.Jobs["https://up.example"])
+	m.Equal([]string{"534419e98c3dcffa6170aea2"}, m.cancelled, "should cancel jobs still running at the deadline")
+}
+
+func (m *MatrixTest) TestBuildErrors() {
+	_, err := MatrixBuilder{}.Build(context.Background())
+	m.Error(err, "should need targets and locations")
+
+	defer m.server().Close()
+	m.builder.Targets = []string{"https://bad.example"}
+	_, err = m.builder.Build(context.Background())
+	m.Error(err, "should fail when nothing could be submitted")
+}
+
+func (m *MatrixTest) TestReachFrom() {
+	job := Job{Details: JobDetails{Done: JobDetail{"denver": {"http": []interface{}{
+		map[string]interface{}{"status": 301, "time": 10},
+		map[string]interface{}{"status": 200, "time": 30},
+	}}}}}
+
+	m.Equal(Cell{Reach: ReachUp, Latency: 40, Status: 200}, reachFrom(job, "denver", []string{"ping", "http"}), "should use http time without a ping")
+	m.Equal(Cell{Reach: ReachUnknown, Reason: "no results"}, reachFrom(job, "riga", []string{"ping", "http"}))
+
+	job.Details.Done["sydney"] = map[string]interface{}{"ping": nil}
+	m.Equal(ReachUnknown, reachFrom(job, "sydney", []string{"ping"}).Reach, "should not count a missing summary as up")
+}
+
+func (m *MatrixTest) TestRender() {
+	matrix := &Matrix{
+		Targets:   []string{"https://up.example"},
+		Locations: []string{"denver", "riga"},
+		Cells: map[string]map[string]Cell{"https://up.example": {
+			"denver": {Reach: ReachUp, Latency: 12.5, Status: 200},
+			"riga":   {Reach: ReachDown, Loss: 100, Reason: "no ping replies"},
+		}},
+	}
+
+	var out bytes.Buffer
+	m.NoError(matrix.Text(&out))
+	m.Equal("TARGET              denver     riga\nhttps://up.example  up 12.5ms  down\n", out.String())
+
+	out.Reset()
+	m.NoError(matrix.Csv(&out))
+	m.Equal("target,location,reach,latency_ms,loss,status,reason\n"+
+		"https://up.example,denver,up,12.5,0,200,\n"+
+		"https://up.example,riga,down,0,100,,no ping replies\n", out.String())
+
+	out.Reset()
+	m.NoError(matrix.Json(&out))
+	var decoded Matrix
+	m.NoError(json.Unmarshal(out.Bytes(), &decoded))
+	m.Equal(matrix.Cells, decoded.Cells, "should round trip through json")
+}
@@ -0,0 +1,201 @@
+// Package notify sends alerts when checks start failing, and again when they
+// recover, over webhooks, email or plain text.
+package notify
+
+import (
+	"context"
+	"errors"
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/schedule"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State is whether an alert is new trouble or the end of it.
+type State string
+
+const (
+	Firing   State = "firing"
+	Resolved State = "resolved"
+)
+
+// Alert is one notification about a check. Failing lists the locations that
+// failed, sorted, and Reasons says why for each. A resolved alert has none.
+type Alert struct {
+	Check   string            `json:"check"`
+	JobId   gowup.JobID       `json:"job_id"`
+	Target  string            `json:"target"`
+	State   State             `json:"state"`
+	Failing []string          `json:"failing"`
+	Reasons map[string]string `json:"reasons"`
+	Time    time.Time         `json:"time"`
+}
+
+// Notifier delivers alerts somewhere.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Dispatcher sends alerts to every notifier, without the noise: a check
+// that keeps failing the same way only alerts once (or every Repeat, if
+// that's set), and one that stops failing gets a resolved alert. What's been
+// sent is tracked per notifier, so one that fails doesn't make the others
+// repeat themselves.
+type Dispatcher struct {
+	Notifiers []Notifier
+	Repeat    time.Duration
+	// Timeout bounds each run of the scheduler sink, so a notifier that
+	// never answers can't hold it up; a minute if it's zero.
+	Timeout time.Duration
+
+	mu sync.Mutex
+	// firing is the last alert each notifier got for each check, by the
+	// notifier's index in Notifiers
+	firing map[string]map[int]sent
+}
+
+// sent is the last alert a notifier got for a check.
+type sent struct {
+	failing []string
+	at      time.Time
+}
+
+// Observe records the latest result of a check and sends whatever alert it
+// calls for, if any. failing maps each failing location to the reason.
+//
+// If a notifier fails, the alert isn't recorded as sent to that notifier, so
+// it goes out to it again next time. The errors come back together.
+func (d *Dispatcher) Observe(ctx context.Context, check string, id gowup.JobID, target string, failing map[string]string) error {
+	firing := Alert{Check: check, JobId: id, Target: target, State: Firing, Failing: []string{}, Reasons: failing, Time: time.Now()}
+	for city := range failing {
+		firing.Failing = append(firing.Failing, city)
+	}
+	sort.Strings(firing.Failing)
+
+	resolved := firing
+	resolved.State, resolved.Failing, resolved.Reasons = Resolved, []string{}, map[string]string{}
+
+	var errs []error
+	for i, notifier := range d.Notifiers {
+		d.mu.Lock()
+		last, wasFiring := d.firing[check][i]
+		d.mu.Unlock()
+
+		alert := firing
+		switch {
+		case len(failing) == 0 && !wasFiring:
+			continue
+		case len(failing) == 0:
+			alert = resolved
+		case wasFiring && equal(last.failing, alert.Failing) && (d.Repeat <= 0 || alert.Time.Sub(last.at) < d.Repeat):
+			continue
+		}
+
+		if err := notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		d.record(check, i, alert)
+	}
+
+	return errors.Join(errs...)
+}
+
+// record remembers that a notifier got an alert.
+func (d *Dispatcher) record(check string, notifier int, alert Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if alert.State == Resolved {
+		delete(d.firing[check], notifier)
+		return
+	}
+
+	if d.firing == nil {
+		d.firing = map[string]map[int]sent{}
+	}
+	if d.firing[check] == nil {
+		d.firing[check] = map[int]sent{}
+	}
+	d.firing[check][notifier] = sent{failing: alert.Failing, at: alert.Time}
+}
+
+// Failures works out which locations in a job failed: any test that errored
+// out, and any location that failed one of the assertions. The location
+// catalog is only needed for assertions that filter by continent.
+func Failures(job *gowup.Job, assertions []gowup.Assertion, locations []gowup.Location) (map[string]string, error) {
+	failing := map[string]string{}
+	add := func(city, reason string) {
+		if failing[city] != "" {
+			reason = failing[city] + "; " + reason
+		}
+		failing[city] = reason
+	}
+
+	for city, tests := range job.Details.Error {
+		names := make([]string, 0, len(tests))
+		for test := range tests {
+			names = append(names, test)
+		}
+		sort.Strings(names)
+		for _, test := range names {
+			add(city, test+" failed")
+		}
+	}
+
+	results, err := job.Check(assertions, locations)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		cities := make([]string, 0, len(result.Locations))
+		for city := range result.Locations {
+			cities = append(cities, city)
+		}
+		sort.Strings(cities)
+
+		for _, city := range cities {
+			outcome := result.Locations[city]
+			// errored tests are already in
+			if outcome.Passed || job.Details.Error[city] != nil {
+				continue
+			}
+			add(city, result.Assertion.String()+": "+outcome.Reason)
+		}
+	}
+
+	return failing, nil
+}
+
+// Sink checks every job the scheduler finishes against the assertions and
+// alerts on the failures.
+func (d *Dispatcher) Sink(assertions []gowup.Assertion, locations []gowup.Location) schedule.Sink {
+	return schedule.SinkFunc(func(check schedule.Check, id gowup.JobID, job *gowup.Job) error {
+		failing, err := Failures(job, assertions, locations)
+		if err != nil {
+			return err
+		}
+
+		timeout := d.Timeout
+		if timeout <= 0 {
+			timeout = time.Minute
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		return d.Observe(ctx, check.Name, id, check.Request.Url, failing)
+	})
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"github.com/ellotheth/gowup"
+	"github.com/ellotheth/gowup/schedule"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// recorder keeps every alert it's sent, and fails if err is set
+type recorder struct {
+	alerts []Alert
+	err    error
+}
+
+func (r *recorder) Notify(ctx context.Context, alert Alert) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+const jobID = gowup.JobID("534419e98c3dcffa6170aeae")
+
+type NotifyTest struct {
+	suite.Suite
+	recorder   *recorder
+	dispatcher *Dispatcher
+}
+
+func TestNotify(t *testing.T) {
+	suite.Run(t, new(NotifyTest))
+}
+
+func (n *NotifyTest) SetupTest() {
+	n.recorder = &recorder{}
+	n.dispatcher = &Dispatcher{Notifiers: []Notifier{n.recorder}}
+}
+
+func (n *NotifyTest) observe(failing map[string]string) error {
+	return n.dispatcher.Observe(context.Background(), "google", jobID, "https://google.com", failing)
+}
+
+func (n *NotifyTest) TestFiring() {
+	n.NoError(n.observe(map[string]string{"riga": "ping failed", "denver": "http failed"}))
+
+	n.Equal(1, len(n.recorder.alerts))
+	alert := n.recorder.alerts[0]
+	n.Equal("google", alert.Check)
+	n.Equal(jobID, alert.JobId)
+	n.Equal("https://google.com", alert.Target)
+	n.Equal(Firing, alert.State)
+	n.Equal([]string{"denver", "riga"}, alert.Failing, "should sort the failing locations")
+	n.Equal("ping failed", alert.Reasons["riga"])
+}
+
+func (n *NotifyTest) TestDedupe() {
+	n.NoError(n.observe(nil))
+	n.Empty(n.recorder.alerts, "should not alert while everything passes")
+
+	n.observe(map[string]string{"riga": "ping failed"})
+	n.observe(map[string]string{"riga": "ping failed again"})
+	n.Equal(1, len(n.recorder.alerts), "should not repeat the same failure")
+
+	n.observe(map[string]string{"riga": "ping failed", "denver": "http failed"})
+	n.Equal(2, len(n.recorder.alerts), "should alert when the failing locations change")
+}
+
+func (n *NotifyTest) TestRepeat() {
+	n.dispatcher.Repeat = time.Millisecond
+
+	n.observe(map[string]string{"riga": "ping failed"})
+	time.Sleep(2 * time.Millisecond)
+	n.observe(map[string]string{"riga": "ping failed"})
+
+	n.Equal(2, len(n.recorder.alerts), "should repeat after the repeat interval")
+}
+
+func (n *NotifyTest) TestRecovery() {
+	n.observe(map[string]string{"riga": "ping failed"})
+	n.observe(map[string]string{})
+
+	n.Equal(2, len(n.recorder.alerts))
+	n.Equal(Resolved, n.recorder.alerts[1].State, "should send a recovery")
+	n.Empty(n.recorder.alerts[1].Failing)
+
+	n.observe(map[string]string{})
+	n.Equal(2, len(n.recorder.alerts), "should only recover once")
+
+	n.observe(map[string]string{"riga": "ping failed"})
+	n.Equal(3, len(n.recorder.alerts), "should fire again after recovering")
+}
+
+func (n *NotifyTest) TestFailedNotifier() {
+	n.recorder.err = errors.New("nope")
+	n.Error(n.observe(map[string]string{"riga": "ping failed"}))
+
+	n.recorder.err = nil
+	n.NoError(n.observe(map[string]string{"riga": "ping failed"}))
+	n.Equal(1, len(n.recorder.alerts), "should try again after a failed notification")
+}
+
+func (n *NotifyTest) TestPerNotifier() {
+	flaky := &recorder{err: errors.New("nope")}
+	n.dispatcher.Notifiers = append(n.dispatcher.Notifiers, flaky)
+
+	n.Error(n.observe(map[string]string{"riga": "ping failed"}))
+	flaky.err = nil
+	n.NoError(n.observe(map[string]string{"riga": "ping failed"}))
+
+	n.Equal(1, len(n.recorder.alerts), "should not repeat alerts that were delivered")
+	n.Equal(1, len(flaky.alerts), "should retry the notifier that failed")
+
+	n.NoError(n.observe(nil))
+	n.Equal(Resolved, n.recorder.alerts[1].State)
+	n.Equal(Resolved, flaky.alerts[1].State)
+}
+
+func (n *NotifyTest) TestFailures() {
+	job := &gowup.Job{Details: gowup.JobDetails{
+		Done: gowup.JobDetail{
+			"denver": {"http": []interface{}{map[string]interface{}{"status": 200}}},
+			"tokyo":  {"http": []interface{}{map[string]interface{}{"status": 503}}},
+		},
+		Error: gowup.JobDetail{"riga": {"http": nil, "ping": nil}},
+	}}
+	assertions := []gowup.Assertion{{Name: "up", Test: "http", Metric: "status", Op: "==", Value: 200}}
+
+	failing, err := Failures(job, assertions, nil)
+	n.NoError(err)
+	n.Equal(map[string]string{
+		"riga":  "http failed; ping failed",
+		"tokyo": "up: status is 503, expected == 200",
+	}, failing)
+
+	_, err = Failures(job, []gowup.Assertion{{Test: "nope"}}, nil)
+	n.Error(err)
+}
+
+// blocker never answers until it's given up on
+type blocker struct{}
+
+func (blocker) Notify(ctx context.Context, alert Alert) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (n *NotifyTest) TestSinkTimeout() {
+	n.dispatcher.Notifiers = []Notifier{blocker{}}
+	n.dispatcher.Timeout = 10 * time.Millisecond
+	check := schedule.Check{Name: "google", Request: gowup.JobRequest{Url: "https://google.com"}}
+
+	err := n.dispatcher.Sink(nil, nil).Send(check, jobID, &gowup.Job{Details: gowup.JobDetails{Error: gowup.JobDetail{"riga": {"ping": nil}}}})
+	n.ErrorIs(err, context.DeadlineExceeded, "should give up on notifiers that never answer")
+}
+
+func (n *NotifyTest) TestSink() {
+	sink := n.dispatcher.Sink(nil, nil)
+	check := schedule.Check{Name: "google", Request: gowup.JobRequest{Url: "https://google.com"}}
+
+	n.NoError(sink.Send(check, jobID, &gowup.Job{Details: gowup.JobDetails{Error: gowup.JobDetail{"riga": {"ping": nil}}}}))
+	n.NoError(sink.Send(check, jobID, &gowup.Job{Details: gowup.JobDetails{Done: gowup.JobDetail{"riga": {"ping": map[string]interface{}{"loss": 0, "avg": 12}}}}}))
+
+	n.Equal(2, len(n.recorder.alerts))
+	n.Equal("https://google.com", n.recorder.alerts[0].Target)
+	n.Equal(Resolved, n.recorder.alerts[1].State)
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Smtp emails each alert. Addr is the server's host:port; Auth can be nil
+// for servers that don't need it. A server that stops answering is given up
+// on when the context is done.
+type Smtp struct {
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   []string
+
+	// send is sendMail, unless a test says otherwise
+	send func(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (s Smtp) Notify(ctx context.Context, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	send := s.send
+	if send == nil {
+		send = sendMail
+	}
+
+	return send(ctx, s.Addr, s.Auth, s.From, s.To, s.message(alert))
+}
+
+// sendMail is smtp.SendMail, except that it gives up when ctx is done.
+func sendMail(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) (err error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// cancelling ctx without a deadline still has to unblock the conn
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	defer func() {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+	}()
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (s Smtp) message(alert Alert) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject(alert))
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body(alert), "\n", "\r\n"))
+
+	return []byte(msg.String())
+}
+
+// subject is the one line summary of an alert, for email subjects and log
+// lines.
+func subject(alert Alert) string {
+	if alert.State == Resolved {
+		return fmt.Sprintf("[RESOLVED] %s (%s)", alert.Check, alert.Target)
+	}
+	return fmt.Sprintf("[FIRING] %s (%s): %d locations failing", alert.Check, alert.Target, len(alert.Failing))
+}
+
+// body is the details of an alert, one location per line.
+func body(alert Alert) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Check: %s\nTarget: %s\nJob: %s\n", alert.Check, alert.Target, alert.JobId)
+
+	if alert.State == Resolved {
+		text.WriteString("\nEvery location is passing again.\n")
+		return text.String()
+	}
+
+	text.WriteString("\nFailing:\n")
+	for _, city := range alert.Failing {
+		fmt.Fprintf(&text, "  %s: %s\n", city, alert.Reasons[city])
+	}
+	return text.String()
+}
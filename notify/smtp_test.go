@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SmtpTest struct {
+	suite.Suite
+	sent []string
+	to   [][]string
+	smtp Smtp
+}
+
+func TestSmtp(t *testing.T) {
+	suite.Run(t, new(SmtpTest))
+}
+
+func (s *SmtpTest) SetupTest() {
+	s.sent, s.to = nil, nil
+	s.smtp = Smtp{Addr: "mail.example.com:25", From: "wup@example.com", To: []string{"ops@example.com", "oncall@example.com"}}
+	s.smtp.send = func(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		s.Equal("mail.example.com:25", addr)
+		s.Equal("wup@example.com", from)
+		s.to = append(s.to, to)
+		s.sent = append(s.sent, string(msg))
+		return nil
+	}
+}
+
+func (s *SmtpTest) TestFiring() {
+	alert := Alert{
+		Check:   "google",
+		JobId:   jobID,
+		Target:  "https://google.com",
+		State:   Firing,
+		Failing: []string{"denver", "riga"},
+		Reasons: map[string]string{"denver": "http failed", "riga": "ping failed"},
+		Time:    time.Unix(1404053589, 0).UTC(),
+	}
+	s.NoError(s.smtp.Notify(context.Background(), alert))
+
+	s.Equal([][]string{{"ops@example.com", "oncall@example.com"}}, s.to)
+	msg := s.sent[0]
+	s.Contains(msg, "To: ops@example.com, oncall@example.com\r\n")
+	s.Contains(msg, "Subject: [FIRING] google (https://google.com): 2 locations failing\r\n")
+	s.Contains(msg, "Date: Sun, 29 Jun 2014 14:53:09 +0000\r\n")
+	s.Contains(msg, "\r\n\r\nCheck: google\r\n")
+	s.Contains(msg, "Job: "+string(jobID)+"\r\n")
+	s.Contains(msg, "  denver: http failed\r\n  riga: ping failed\r\n")
+	s.False(strings.Contains(strings.ReplaceAll(msg, "\r\n", ""), "\n"), "should only use CRLF line endings")
+}
+
+func (s *SmtpTest) TestResolved() {
+	s.NoError(s.smtp.Notify(context.Background(), Alert{Check: "google", Target: "https://google.com", State: Resolved}))
+	s.Contains(s.sent[0], "Subject: [RESOLVED] google (https://google.com)\r\n")
+	s.Contains(s.sent[0], "passing again")
+}
+
+func (s *SmtpTest) TestCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Error(s.smtp.Notify(ctx, Alert{}))
+	s.Empty(s.sent, "should not send once the context is done")
+}
+
+// serve runs a bare bones SMTP server that handles one connection with
+// handle, and returns its address
+func (s *SmtpTest) serve(handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func (s *SmtpTest) TestSendMail() {
+	received := make(chan string, 1)
+	addr := s.serve(func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		var data strings.Builder
+		reading := false
+
+		fmt.Fprint(conn, "220 localhost\r\n")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case reading && line == ".\r\n":
+				reading = false
+				fmt.Fprint(conn, "250 ok\r\n")
+			case reading:
+				data.WriteString(line)
+			case strings.HasPrefix(line, "DATA"):
+				reading = true
+				fmt.Fprint(conn, "354 go ahead\r\n")
+			case strings.HasPrefix(line, "QUIT"):
+				fmt.Fprint(conn, "221 bye\r\n")
+				received <- data.String()
+				return
+			default:
+				fmt.Fprint(conn, "250 ok\r\n")
+			}
+		}
+	})
+
+	err := Smtp{Addr: addr, From: "wup@example.com", To: []string{"ops@example.com"}}.Notify(context.Background(), Alert{Check: "google", State: Resolved})
+	s.NoError(err)
+	s.Contains(<-received, "Subject: [RESOLVED] google")
+}
+
+func (s *SmtpTest) TestStalledServer() {
+	addr := s.serve(func(conn net.Conn) {
+		// never says hello
+		time.Sleep(2 * time.Second)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Smtp{Addr: addr, From: "wup@example.com", To: []string{"ops@example.com"}}.Notify(ctx, Alert{Check: "google"})
+	s.ErrorIs(err, context.DeadlineExceeded, "should give up on a server that never answers")
+	s.Less(time.Since(start), time.Second)
+}
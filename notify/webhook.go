@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// webhookClient is used when a Webhook doesn't have its own client.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// Webhook posts each alert as JSON to Url. Header is added to every
+// request, for auth tokens and the like. Anything but a 2xx is an error.
+// Without a Client, requests give up after 30 seconds.
+type Webhook struct {
+	Url    string
+	Header http.Header
+	Client *http.Client
+}
+
+func (w Webhook) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range w.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = webhookClient
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", w.Url, response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type WebhookTest struct {
+	suite.Suite
+	received []map[string]interface{}
+	headers  []http.Header
+	status   int
+	server   *httptest.Server
+}
+
+func TestWebhook(t *testing.T) {
+	suite.Run(t, new(WebhookTest))
+}
+
+func (w *WebhookTest) SetupTest() {
+	w.received, w.headers, w.status = nil, nil, 200
+	w.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.received = append(w.received, payload)
+		w.headers = append(w.headers, r.Header)
+		rw.WriteHeader(w.status)
+	}))
+}
+
+func (w *WebhookTest) TearDownTest() {
+	w.server.Close()
+}
+
+func (w *WebhookTest) TestPayload() {
+	hook := Webhook{Url: w.server.URL, Header: http.Header{"Authorization": {"Bearer herp"}}}
+	alert := Alert{
+		Check:   "google",
+		JobId:   jobID,
+		Target:  "https://google.com",
+		State:   Firing,
+		Failing: []string{"riga"},
+		Reasons: map[string]string{"riga": "ping failed"},
+		Time:    time.Unix(1404053589, 0).UTC(),
+	}
+
+	w.NoError(hook.Notify(context.Background(), alert))
+
+	w.Equal(1, len(w.received))
+	w.Equal(map[string]interface{}{
+		"check":   "google",
+		"job_id":  string(jobID),
+		"target":  "https://google.com",
+		"state":   "firing",
+		"failing": []interface{}{"riga"},
+		"reasons": map[string]interface{}{"riga": "ping failed"},
+		"time":    "2014-06-29T14:53:09Z",
+	}, w.received[0])
+	w.Equal("application/json", w.headers[0].Get("Content-Type"))
+	w.Equal("Bearer herp", w.headers[0].Get("Authorization"), "should send the extra headers")
+}
+
+func (w *WebhookTest) TestErrors() {
+	w.status = 500
+	err := Webhook{Url: w.server.URL}.Notify(context.Background(), Alert{})
+	w.Error(err, "should fail on error statuses")
+	w.Contains(err.Error(), "500")
+
+	w.server.Close()
+	w.Error(Webhook{Url: w.server.URL}.Notify(context.Background(), Alert{}), "should fail when nothing's listening")
+}
+
+func (w *WebhookTest) TestDispatcher() {
+	d := &Dispatcher{Notifiers: []Notifier{Webhook{Url: w.server.URL}}}
+
+	w.NoError(d.Observe(context.Background(), "google", jobID, "https://google.com", map[string]string{"riga": "ping failed"}))
+	w.NoError(d.Observe(context.Background(), "google", jobID, "https://google.com", map[string]string{"riga": "ping failed"}))
+	w.NoError(d.Observe(context.Background(), "google", jobID, "https://google.com", nil))
+
+	w.Equal(2, len(w.received))
+	w.Equal("firing", w.received[0]["state"])
+	w.Equal("resolved", w.received[1]["state"])
+}
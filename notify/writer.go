@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writer writes each alert as a line of text, for stdout or a log file.
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// NewFile appends alerts to the file at path, creating it if it has to.
+func NewFile(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: f, file: f}, nil
+}
+
+// Close closes the file from NewFile. It leaves any other writer alone.
+func (w *Writer) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+func (w *Writer) Notify(ctx context.Context, alert Alert) error {
+	line := alert.Time.UTC().Format(time.RFC3339) + " " + subject(alert)
+	if alert.State == Firing {
+		reasons := make([]string, 0, len(alert.Failing))
+		for _, city := range alert.Failing {
+			reasons = append(reasons, city+" ("+alert.Reasons[city]+")")
+		}
+		line += ": " + strings.Join(reasons, ", ")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.w, "%s job=%s\n", line, alert.JobId)
+	return err
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type WriterTest struct {
+	suite.Suite
+	alert Alert
+}
+
+func TestWriter(t *testing.T) {
+	suite.Run(t, new(WriterTest))
+}
+
+func (w *WriterTest) SetupTest() {
+	w.alert = Alert{
+		Check:   "google",
+		JobId:   jobID,
+		Target:  "https://google.com",
+		State:   Firing,
+		Failing: []string{"denver", "riga"},
+		Reasons: map[string]string{"denver": "http failed", "riga": "ping failed"},
+		Time:    time.Unix(1404053589, 0),
+	}
+}
+
+func (w *WriterTest) TestWriter() {
+	var out bytes.Buffer
+	writer := NewWriter(&out)
+
+	w.NoError(writer.Notify(context.Background(), w.alert))
+	w.alert.State = Resolved
+	w.NoError(writer.Notify(context.Background(), w.alert))
+
+	w.Equal("2014-06-29T14:53:09Z [FIRING] google (https://google.com): 2 locations failing: denver (http failed), riga (ping failed) job="+string(jobID)+"\n"+
+		"2014-06-29T14:53:09Z [RESOLVED] google (https://google.com) job="+string(jobID)+"\n", out.String())
+	w.NoError(writer.Close(), "should leave other writers alone")
+}
+
+func (w *WriterTest) TestFile() {
+	path := filepath.Join(w.T().TempDir(), "alerts.log")
+
+	for i := 0; i < 2; i++ {
+		writer, err := NewFile(path)
+		w.NoError(err)
+		w.NoError(writer.Notify(context.Background(), w.alert))
+		w.NoError(writer.Close())
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	w.NoError(err)
+	w.Equal(2, bytes.Count(raw, []byte("\n")), "should append to the file")
+
+	_, err = NewFile(filepath.Join(path, "nope"))
+	w.Error(err)
+}
@@ -0,0 +1,98 @@
+package gowup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+const mask = "****"
+
+// String keeps the token out of logs, including %v and %+v.
+func (api WIU) String() string {
+	return fmt.Sprintf("{Client:%s Token:%s}", api.Client, mask)
+}
+
+// GoString keeps the token out of %#v.
+func (api WIU) GoString() string {
+	return fmt.Sprintf("gowup.WIU{Client:%q, Token:%q}", api.Client, mask)
+}
+
+func (c Credentials) String() string {
+	return fmt.Sprintf("{Client:%s Token:%s}", c.Client, mask)
+}
+
+func (c Credentials) GoString() string {
+	return fmt.Sprintf("gowup.Credentials{Client:%q, Token:%q}", c.Client, mask)
+}
+
+// DumpRequest is httputil.DumpRequestOut with the auth header and any copy of
+// the token masked, so it's safe to log.
+func (api WIU) DumpRequest(req *http.Request, body bool) ([]byte, error) {
+	clone := req.Clone(req.Context())
+	clone.Header = redactHeaders(req.Header)
+
+	// the body can only be read once, so put it back for the real request
+	if body && req.Body != nil {
+		raw, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(raw))
+		clone.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	}
+
+	dump, err := httputil.DumpRequestOut(clone, body)
+	if err != nil {
+		return nil, api.redact(err)
+	}
+
+	return []byte(api.redactString(string(dump))), nil
+}
+
+// DumpResponse is httputil.DumpResponse with any copy of the token masked.
+func (api WIU) DumpResponse(response *http.Response, body bool) ([]byte, error) {
+	clone := *response
+	clone.Header = redactHeaders(response.Header)
+
+	dump, err := httputil.DumpResponse(&clone, body)
+	response.Body = clone.Body
+	if err != nil {
+		return nil, api.redact(err)
+	}
+
+	return []byte(api.redactString(string(dump))), nil
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range []string{"Auth", "Authorization"} {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, mask)
+		}
+	}
+	return redacted
+}
+
+func (api WIU) redactString(s string) string {
+	if api.Token == "" {
+		return s
+	}
+	return strings.Replace(s, api.Token, mask, -1)
+}
+
+// redact masks the token in an error, just in case something (a URL, a
+// server echoing headers back) put it there.
+func (api WIU) redact(err error) error {
+	if err == nil || api.Token == "" || !strings.Contains(err.Error(), api.Token) {
+		return err
+	}
+	if _, ok := err.(*Error); ok {
+		return &Error{msg: api.redactString(err.Error())}
+	}
+	return errors.New(api.redactString(err.Error()))
+}
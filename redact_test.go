@@ -0,0 +1,126 @@
+package gowup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type RedactTest struct {
+	suite.Suite
+	api WIU
+}
+
+func TestRedact(t *testing.T) {
+	suite.Run(t, new(RedactTest))
+}
+
+func (r *RedactTest) SetupTest() {
+	r.api = WIU{Client: "herp", Token: "sekrit-token"}
+}
+
+func (r *RedactTest) TestFormatting() {
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		formatted := fmt.Sprintf(format, r.api)
+		r.NotContains(formatted, "sekrit-token", "should mask the token with "+format)
+		r.Contains(formatted, "herp", "should keep the client with "+format)
+	}
+
+	r.Equal(`gowup.WIU{Client:"herp", Token:"****"}`, fmt.Sprintf("%#v", r.api))
+
+	creds := Credentials{Client: "herp", Token: "sekrit-token"}
+	r.NotContains(fmt.Sprintf("%+v", Config{Profiles: map[string]Credentials{"x": creds}}), "sekrit-token", "should mask nested credentials")
+	r.NotContains(fmt.Sprintf("%#v", creds), "sekrit-token")
+}
+
+func (r *RedactTest) TestDumpRequest() {
+	req, _ := http.NewRequest("POST", "https://api.wheresitup.com/v4/jobs", strings.NewReader(`{"token": "sekrit-token"}`))
+	r.api.setHeaders(req, nil)
+
+	dump, err := r.api.DumpRequest(req, true)
+	r.NoError(err, "should not return an error")
+	r.NotContains(string(dump), "sekrit-token", "should mask the token everywhere")
+	r.Contains(string(dump), "Auth: ****", "should mask the auth header")
+	r.Contains(string(dump), `{"token": "****"}`, "should include the body")
+
+	r.Equal("Bearer herp sekrit-token", req.Header.Get("Auth"), "should not touch the real request")
+	body, _ := ioutil.ReadAll(req.Body)
+	r.Equal(`{"token": "sekrit-token"}`, string(body), "should leave the body readable")
+}
+
+func (r *RedactTest) TestDumpResponse() {
+	response := &http.Response{
+		StatusCode: 200,
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Authorization": {"Bearer herp sekrit-token"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString("echo sekrit-token")),
+	}
+
+	dump, err := r.api.DumpResponse(response, true)
+	r.NoError(err, "should not return an error")
+	r.NotContains(string(dump), "sekrit-token")
+
+	body, _ := ioutil.ReadAll(response.Body)
+	r.Equal("echo sekrit-token", string(body), "should leave the body readable")
+}
+
+func (r *RedactTest) TestRedactError() {
+	r.Nil(r.api.redact(nil))
+	r.Equal("nope", r.api.redact(errors.New("nope")).Error())
+	r.Equal("bad header Bearer herp ****", r.api.redact(errors.New("bad header Bearer herp sekrit-token")).Error())
+}
+
+// every error the client can produce, with a server that echoes the auth
+// header back wherever it can
+func (r *RedactTest) TestErrorsNeverIncludeToken() {
+	echo := func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Header.Get("Auth")))
+	}
+	handlers := map[string]http.HandlerFunc{
+		"echo":        echo,
+		"bad request": func(w http.ResponseWriter, req *http.Request) { http.Error(w, req.Header.Get("Auth"), 400) },
+		"wrong type":  func(w http.ResponseWriter, req *http.Request) { w.Write([]byte(`"` + req.Header.Get("Auth") + `"`)) },
+		"no job id": func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(`{"message": "` + req.Header.Get("Auth") + `"}`))
+		},
+		"no sources": func(w http.ResponseWriter, req *http.Request) { w.Write([]byte(`{}`)) },
+	}
+
+	for name, handler := range handlers {
+		server := httptest.NewServer(handler)
+		apiEntryPoint = server.URL
+
+		var errs []error
+		_, err := r.api.Locations()
+		errs = append(errs, err)
+		_, err = r.api.Jobs()
+		errs = append(errs, err)
+		_, err = r.api.Job("534419e98c3dcffa6170aeae")
+		errs = append(errs, err)
+		_, err = r.api.Submit(&JobRequest{Url: "sekrit-token"})
+		errs = append(errs, err)
+
+		server.Close()
+
+		for _, err := range errs {
+			if err != nil {
+				r.NotContains(err.Error(), "sekrit-token", "should not leak the token ("+name+")")
+			}
+		}
+	}
+
+	// and with nothing listening at all
+	_, err := r.api.Jobs()
+	r.Error(err)
+	r.NotContains(err.Error(), "sekrit-token")
+
+	_, err = r.api.Job("sekrit-token")
+	r.NotContains(err.Error(), "sekrit-token")
+}
@@ -0,0 +1,202 @@
+package gowup
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportRow is one location in a test's table.
+type reportRow struct {
+	Location string
+	State    string
+	Summary  string
+	Raw      string
+}
+
+type reportTest struct {
+	Name string
+	Rows []reportRow
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Where's it Up: {{.Url}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.2em 1em; }
+dt { font-weight: bold; }
+pre { white-space: pre-wrap; font-size: 0.85em; background: #f6f6f6; padding: 0.5em; }
+.complete { background: #e6f4ea; }
+.in_progress { background: #fff8e1; }
+.error { background: #fdecea; }
+</style>
+</head>
+<body>
+<h1>Where's it Up report</h1>
+<dl>
+<dt>Job</dt><dd>{{.Id}}</dd>
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>URL</dt><dd>{{.Url}}</dd>
+<dt>IP</dt><dd>{{.Ip}}</dd>
+<dt>Started</dt><dd>{{.Start}}</dd>
+<dt>Expires</dt><dd>{{.Expiry}}</dd>
+</dl>
+{{range .Tests}}
+<h2>{{.Name}}</h2>
+<table>
+<tr><th>Location</th><th>State</th><th>Result</th></tr>
+{{range .Rows}}<tr class="{{.State}}">
+<td>{{.Location}}</td>
+<td>{{.State}}</td>
+<td>{{.Summary}}{{if .Raw}}
+<details><summary>Raw output</summary><pre>{{.Raw}}</pre></details>{{end}}</td>
+</tr>
+{{end}}</table>
+{{else}}
+<p>No results yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// Report writes a self-contained HTML page for the job: the summary, a table
+// of results per test, and the raw output for each location tucked away in a
+// collapsible block.
+func (j Job) Report(w io.Writer) error {
+	data := struct {
+		Id     JobID
+		Status JobStatus
+		Url    string
+		Ip     string
+		Start  string
+		Expiry string
+		Tests  []reportTest
+	}{
+		Id:     j.Id,
+		Status: j.Status(),
+		Ip:     j.Summary.Ip,
+		Start:  formatTime(j.Summary.StartTime),
+		Expiry: formatTime(j.Summary.ExpireTime),
+	}
+	if j.Summary.Url.URL != nil {
+		data.Url = j.Summary.Url.String()
+	}
+
+	tests := map[string][]reportRow{}
+	for _, state := range []struct {
+		name   string
+		detail JobDetail
+	}{
+		{"complete", j.Details.Done},
+		{"in_progress", j.Details.NotDone},
+		{"error", j.Details.Error},
+	} {
+		for city, results := range state.detail {
+			for test, summary := range results {
+				row := reportRow{Location: city, State: state.name, Raw: rawText(j.Details.Raw[city][test])}
+				switch state.name {
+				case "complete":
+					row.Summary = summarize(test, summary)
+				case "in_progress":
+					row.Summary = "Still running"
+				case "error":
+					row.Summary = "Failed"
+					if summary != nil {
+						row.Summary += ": " + rawText(summary)
+					}
+				}
+				tests[test] = append(tests[test], row)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(tests))
+	for test := range tests {
+		names = append(names, test)
+	}
+	sort.Strings(names)
+
+	for _, test := range names {
+		rows := tests[test]
+		sort.Slice(rows, func(a, b int) bool { return rows[a].Location < rows[b].Location })
+		data.Tests = append(data.Tests, reportTest{Name: test, Rows: rows})
+	}
+
+	return reportTemplate.Execute(w, data)
+}
+
+// summarize describes a test result in a few words, falling back to the
+// summary's json for tests it doesn't know.
+func summarize(test string, summary interface{}) string {
+	switch test {
+	case "ping":
+		var ping PingResult
+		if decodeSummary(summary, &ping) == nil {
+			return fmt.Sprintf("%.1f ms avg (%.1f-%.1f), %g%% loss", ping.Avg, ping.Min, ping.Max, ping.Loss)
+		}
+	case "http":
+		var http HttpResult
+		if decodeSummary(summary, &http) == nil && http.Final() != nil {
+			text := fmt.Sprintf("%d in %.0f ms (%d requests)", http.Final().Status, http.Time(), len(http))
+			if tls := http.Tls(); tls != nil {
+				text += ", certificate expires " + tls.Expiry.UTC().Format("2006-01-02")
+			}
+			return text
+		}
+	case "dig":
+		var dig DigResult
+		if decodeSummary(summary, &dig) == nil {
+			if len(dig.Answers) == 0 {
+				return "No answers"
+			}
+			return strings.Join(dig.Data(), ", ")
+		}
+	case "fast":
+		var fast FastResult
+		if decodeSummary(summary, &fast) == nil && (fast.Speed > 0 || fast.Bytes > 0) {
+			if mbps, err := fast.Mbps(); err == nil {
+				return fmt.Sprintf("%.1f Mbps", mbps)
+			}
+		}
+	case "trace":
+		var trace TraceResult
+		if decodeSummary(summary, &trace) == nil && len(trace) > 0 {
+			last := trace[len(trace)-1]
+			return fmt.Sprintf("%d hops, last %s at %.1f ms", len(trace), last.Ip, last.Avg())
+		}
+	}
+
+	return rawText(summary)
+}
+
+func rawText(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+
+	encoded, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Sprint(raw)
+	}
+	return string(encoded)
+}
+
+func formatTime(t Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC1123)
+}
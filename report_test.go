@@ -0,0 +1,72 @@
+package gowup
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ReportTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestReport(t *testing.T) {
+	suite.Run(t, new(ReportTest))
+}
+
+func (r *ReportTest) SetupTest() {
+	r.job = Job{}
+	json.Unmarshal([]byte(`{
+	    "request": {
+	        "url": "https://google.com",
+	        "ip": "107.4.56.245",
+	        "start_time": 1404053589,
+	        "expiry": {"sec": 1405263189, "usec": 0}
+	    },
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "ping": {"raw": "PING google.com <b>", "summary": {"avg": 12.5, "min": 10, "max": 15, "loss": 0}},
+	                "http": {"raw": "HTTP/1.1 200 OK", "summary": [{"status": 200, "time": 80}]}
+	            }
+	        },
+	        "error": {"tokyo": {"ping": {"raw": "unreachable", "summary": "timed out"}}},
+	        "in_progress": {"riga": {"http": {}}}
+	    }
+	}`), &r.job)
+}
+
+func (r *ReportTest) TestReport() {
+	var out bytes.Buffer
+	r.NoError(r.job.Report(&out))
+	html := out.String()
+
+	r.Contains(html, "<dd>https://google.com</dd>", "should include the url")
+	r.Contains(html, "<dd>107.4.56.245</dd>", "should include the ip")
+	r.Contains(html, "<dd>Sun, 29 Jun 2014 14:53:09 UTC</dd>", "should include the start time")
+	r.Contains(html, "<h2>http</h2>", "should have a table per test")
+	r.Contains(html, "12.5 ms avg (10.0-15.0), 0% loss", "should summarize pings")
+	r.Contains(html, "200 in 80 ms (1 requests)", "should summarize http")
+	r.Contains(html, `<tr class="error">`, "should mark errors")
+	r.Contains(html, "Failed: timed out")
+	r.Contains(html, `<tr class="in_progress">`, "should mark unfinished tests")
+	r.Contains(html, "<pre>PING google.com &lt;b&gt;</pre>", "should escape raw output")
+	r.NotContains(html, "<script", "should not need any scripts")
+	r.NotContains(html, "<link", "should not need any external assets")
+}
+
+func (r *ReportTest) TestEmptyReport() {
+	var out bytes.Buffer
+	r.NoError(Job{}.Report(&out))
+	r.Contains(out.String(), "No results yet.")
+}
+
+func (r *ReportTest) TestSummarizeFast() {
+	r.Equal("80.0 Mbps", summarize("fast", map[string]interface{}{"speed": 10, "unit": "MB/s"}))
+}
+
+func (r *ReportTest) TestSummarizeUnknownTest() {
+	r.Equal("{\n  \"hops\": 10\n}", summarize("nope", map[string]interface{}{"hops": 10}))
+}
@@ -0,0 +1,258 @@
+package gowup
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// PingResult is the summary of a ping test from one location. Times holds
+// the round trip time of each reply, in milliseconds.
+type PingResult struct {
+	Transmitted int       `json:"transmitted"`
+	Received    int       `json:"received"`
+	Loss        float64   `json:"loss"`
+	Min         float64   `json:"min"`
+	Avg         float64   `json:"avg"`
+	Max         float64   `json:"max"`
+	Mdev        float64   `json:"mdev"`
+	Times       []float64 `json:"times"`
+}
+
+// HttpResponse is one request in an HTTP test. Time is in milliseconds.
+// Tls is only there for HTTPS requests, and Timing only when the location
+// reports the phases.
+type HttpResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Time    float64           `json:"time"`
+	Tls     *TlsInfo          `json:"tls"`
+	Timing  *HttpTiming       `json:"timing"`
+}
+
+// TlsInfo is the certificate the server presented.
+type TlsInfo struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	Expiry  Time   `json:"expiry"`
+}
+
+// HttpTiming breaks a request down by phase, in milliseconds. Each phase is
+// its own duration, not the time since the start; Total covers the lot.
+type HttpTiming struct {
+	Dns     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Tls     float64 `json:"tls"`
+	Ttfb    float64 `json:"ttfb"`
+	Total   float64 `json:"total"`
+}
+
+// Redirect is one hop in a redirect chain: the status, and where it
+// pointed.
+type Redirect struct {
+	Status   int
+	Location string
+}
+
+// Header looks up a response header, ignoring case.
+func (r HttpResponse) Header(name string) string {
+	if value, ok := r.Headers[name]; ok {
+		return value
+	}
+	for key, value := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// Redirected is true for a 3xx with somewhere to go.
+func (r HttpResponse) Redirected() bool {
+	return r.Status >= 300 && r.Status < 400 && r.Header("Location") != ""
+}
+
+// HttpResult is the summary of an HTTP test from one location: every
+// response in the redirect chain, in the order they were requested.
+type HttpResult []HttpResponse
+
+// Final returns the last response in the chain, or nil if there isn't one.
+func (h HttpResult) Final() *HttpResponse {
+	if len(h) == 0 {
+		return nil
+	}
+	return &h[len(h)-1]
+}
+
+// Status is the final status, or 0 if there were no responses.
+func (h HttpResult) Status() int {
+	if final := h.Final(); final != nil {
+		return final.Status
+	}
+	return 0
+}
+
+// Redirects lists the redirects that led to the final response.
+func (h HttpResult) Redirects() []Redirect {
+	redirects := []Redirect{}
+	for _, response := range h {
+		if response.Redirected() {
+			redirects = append(redirects, Redirect{Status: response.Status, Location: response.Header("Location")})
+		}
+	}
+	return redirects
+}
+
+// Tls is the certificate from the final response, or nil if it wasn't
+// HTTPS.
+func (h HttpResult) Tls() *TlsInfo {
+	if final := h.Final(); final != nil {
+		return final.Tls
+	}
+	return nil
+}
+
+// Time is the total time spent on the whole redirect chain.
+func (h HttpResult) Time() float64 {
+	total := 0.0
+	for _, response := range h {
+		total += response.Time
+	}
+	return total
+}
+
+// DigAnswer is one record from the answer section of a dig test.
+type DigAnswer struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Ttl  int    `json:"ttl"`
+	Data string `json:"data"`
+}
+
+// DigResult is the summary of a dig test from one location.
+type DigResult struct {
+	Answers []DigAnswer `json:"answers"`
+}
+
+// Data lists the data of every answer, e.g. the addresses for an A lookup.
+func (d DigResult) Data() []string {
+	data := make([]string, 0, len(d.Answers))
+	for _, answer := range d.Answers {
+		data = append(data, answer.Data)
+	}
+	return data
+}
+
+// TraceHop is one hop of a traceroute. Rtt has the time for every probe that
+// came back, in milliseconds; Timeouts counts the ones that didn't. A hop
+// where nothing came back has no Ip.
+type TraceHop struct {
+	Hop      int       `json:"hop"`
+	Host     string    `json:"host"`
+	Ip       string    `json:"ip"`
+	Rtt      []float64 `json:"rtt"`
+	Timeouts int       `json:"timeouts"`
+}
+
+// TraceResult is the summary of a trace test from one location, one entry
+// per hop.
+type TraceResult []TraceHop
+
+// Responded is false when every probe to the hop timed out.
+func (h TraceHop) Responded() bool {
+	return h.Ip != "" && h.Ip != "*"
+}
+
+// Avg is the average round trip time to the hop, 0 if it didn't respond.
+func (h TraceHop) Avg() float64 {
+	if len(h.Rtt) == 0 {
+		return 0
+	}
+	return NewStats(h.Rtt).Avg
+}
+
+// PingResults decodes the finished ping tests in the job, keyed by location.
+func (j Job) PingResults() (map[string]PingResult, error) {
+	results := map[string]PingResult{}
+	for city, tests := range j.Details.Done {
+		summary, ok := tests["ping"]
+		if !ok || summary == nil {
+			continue
+		}
+
+		var result PingResult
+		if err := decodeSummary(summary, &result); err != nil {
+			return nil, &Error{msg: "Invalid ping result from " + city + ": " + err.Error()}
+		}
+		results[city] = result
+	}
+
+	return results, nil
+}
+
+// HttpResults decodes the finished HTTP tests in the job, keyed by location.
+func (j Job) HttpResults() (map[string]HttpResult, error) {
+	results := map[string]HttpResult{}
+	for city, tests := range j.Details.Done {
+		summary, ok := tests["http"]
+		if !ok || summary == nil {
+			continue
+		}
+
+		var result HttpResult
+		if err := decodeSummary(summary, &result); err != nil {
+			return nil, &Error{msg: "Invalid http result from " + city + ": " + err.Error()}
+		}
+		results[city] = result
+	}
+
+	return results, nil
+}
+
+// DigResults decodes the finished dig tests in the job, keyed by location.
+func (j Job) DigResults() (map[string]DigResult, error) {
+	results := map[string]DigResult{}
+	for city, tests := range j.Details.Done {
+		summary, ok := tests["dig"]
+		if !ok || summary == nil {
+			continue
+		}
+
+		var result DigResult
+		if err := decodeSummary(summary, &result); err != nil {
+			return nil, &Error{msg: "Invalid dig result from " + city + ": " + err.Error()}
+		}
+		results[city] = result
+	}
+
+	return results, nil
+}
+
+// TraceResults decodes the finished trace tests in the job, keyed by location.
+func (j Job) TraceResults() (map[string]TraceResult, error) {
+	results := map[string]TraceResult{}
+	for city, tests := range j.Details.Done {
+		summary, ok := tests["trace"]
+		if !ok || summary == nil {
+			continue
+		}
+
+		var result TraceResult
+		if err := decodeSummary(summary, &result); err != nil {
+			return nil, &Error{msg: "Invalid trace result from " + city + ": " + err.Error()}
+		}
+		results[city] = result
+	}
+
+	return results, nil
+}
+
+// JobDetail keeps the summaries as generic json values, so the easiest way
+// to get them into a real type is to round trip them through the encoder.
+func decodeSummary(summary interface{}, dest interface{}) error {
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, dest)
+}
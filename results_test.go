@@ -0,0 +1,121 @@
+package gowup
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ResultsTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestResults(t *testing.T) {
+	suite.Run(t, new(ResultsTest))
+}
+
+func (r *ResultsTest) SetupTest() {
+	r.job = Job{}
+	json.Unmarshal([]byte(`{
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "ping": {
+	                    "raw": "PING google.com",
+	                    "summary": {
+	                        "transmitted": 3,
+	                        "received": 3,
+	                        "loss": 0,
+	                        "min": 10.5,
+	                        "avg": 12,
+	                        "max": 14,
+	                        "mdev": 1.4,
+	                        "times": [10.5, 11.5, 14]
+	                    }
+	                },
+	                "http": {
+	                    "raw": "HTTP/1.1 301",
+	                    "summary": [
+	                        {"status": 301, "headers": {"location": "https://www.google.com/"}, "time": 40},
+	                        {
+	                            "status": 200,
+	                            "headers": {"Server": "gws"},
+	                            "time": 60,
+	                            "tls": {"subject": "CN=www.google.com", "issuer": "CN=GTS CA 1C3", "expiry": 1404053589},
+	                            "timing": {"dns": 5, "connect": 10, "tls": 20, "ttfb": 15, "total": 60}
+	                        }
+	                    ]
+	                }
+	            },
+	            "sydney": {
+	                "ping": {
+	                    "raw": "PING google.com",
+	                    "summary": {"transmitted": 3, "received": 0, "loss": 100}
+	                }
+	            }
+	        },
+	        "error": [],
+	        "in_progress": []
+	    }
+	}`), &r.job)
+}
+
+func (r *ResultsTest) TestPingResults() {
+	results, err := r.job.PingResults()
+	r.NoError(err, "should not return an error")
+	r.Equal(2, len(results), "should decode every ping")
+	r.Equal([]float64{10.5, 11.5, 14}, results["denver"].Times, "should decode the reply times")
+	r.Equal(float64(100), results["sydney"].Loss, "should decode packet loss")
+}
+
+func (r *ResultsTest) TestHttpResults() {
+	results, err := r.job.HttpResults()
+	r.NoError(err, "should not return an error")
+	r.Equal(1, len(results), "should only decode locations with http tests")
+
+	chain := results["denver"]
+	r.Equal(2, len(chain), "should keep the whole redirect chain")
+	r.Equal(200, chain.Final().Status, "should find the final response")
+	r.Equal(float64(100), chain.Time(), "should total the chain time")
+}
+
+func (r *ResultsTest) TestHttpDetails() {
+	results, _ := r.job.HttpResults()
+	chain := results["denver"]
+
+	r.Equal(200, chain.Status())
+	r.Equal([]Redirect{{Status: 301, Location: "https://www.google.com/"}}, chain.Redirects(), "should find the location header in any case")
+	r.Equal("gws", chain.Final().Header("server"))
+	r.Nil(chain[0].Tls, "should leave out missing certificates")
+
+	tls := chain.Tls()
+	r.Equal("CN=www.google.com", tls.Subject)
+	r.Equal("CN=GTS CA 1C3", tls.Issuer)
+	r.Equal(int64(1404053589), tls.Expiry.Unix())
+	r.Equal(HttpTiming{Dns: 5, Connect: 10, Tls: 20, Ttfb: 15, Total: 60}, *chain.Final().Timing)
+}
+
+func (r *ResultsTest) TestEmptyHttpResult() {
+	r.Nil(HttpResult{}.Final(), "should not have a final response")
+	r.Equal(0, HttpResult{}.Status())
+	r.Nil(HttpResult{}.Tls())
+	r.Empty(HttpResult{}.Redirects())
+}
+
+func (r *ResultsTest) TestMissingSummary() {
+	r.job.Details.Done["sydney"]["ping"] = nil
+
+	pings, err := r.job.PingResults()
+	r.NoError(err)
+	_, ok := pings["sydney"]
+	r.False(ok, "should not treat a missing summary as a perfect ping")
+}
+
+func (r *ResultsTest) TestBadSummary() {
+	r.job.Details.Done["denver"]["ping"] = "NOPE"
+
+	_, err := r.job.PingResults()
+	r.Error(err, "should reject summaries in the wrong format")
+	r.Contains(err.Error(), "denver")
+}
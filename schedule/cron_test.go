@@ -0,0 +1,60 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type CronTest struct {
+	suite.Suite
+	start time.Time
+}
+
+func TestCron(t *testing.T) {
+	suite.Run(t, new(CronTest))
+}
+
+func (c *CronTest) SetupTest() {
+	// a wednesday
+	c.start = time.Date(2014, time.June, 25, 10, 7, 30, 0, time.UTC)
+}
+
+func (c *CronTest) next(spec string) time.Time {
+	s, err := Parse(spec)
+	c.Require().NoError(err, "should parse '"+spec+"'")
+	return s.Next(c.start)
+}
+
+func (c *CronTest) TestEvery() {
+	c.Equal(c.start.Add(90*time.Second), c.next("@every 90s"))
+	c.Equal(c.start.Add(time.Hour), Every(time.Hour).Next(c.start))
+}
+
+func (c *CronTest) TestFields() {
+	c.Equal(time.Date(2014, time.June, 25, 10, 8, 0, 0, time.UTC), c.next("* * * * *"), "should run next minute")
+	c.Equal(time.Date(2014, time.June, 25, 10, 15, 0, 0, time.UTC), c.next("*/15 * * * *"), "should handle steps")
+	c.Equal(time.Date(2014, time.June, 25, 11, 5, 0, 0, time.UTC), c.next("5 * * * *"), "should roll over the hour")
+	c.Equal(time.Date(2014, time.June, 25, 13, 0, 0, 0, time.UTC), c.next("0 9-17/4 * * *"), "should handle stepped ranges")
+	c.Equal(time.Date(2014, time.June, 26, 9, 0, 0, 0, time.UTC), c.next("0 9 * * *"), "should roll over the day")
+	c.Equal(time.Date(2014, time.June, 28, 0, 0, 0, 0, time.UTC), c.next("0 0 * * 6"), "should handle days of the week")
+	c.Equal(time.Date(2014, time.June, 29, 0, 0, 0, 0, time.UTC), c.next("0 0 * * 7"), "should treat 7 as sunday")
+	c.Equal(time.Date(2014, time.July, 1, 0, 0, 0, 0, time.UTC), c.next("@monthly"), "should expand shortcuts")
+	c.Equal(time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC), c.next("0 0 29 2 *"), "should find leap days")
+}
+
+func (c *CronTest) TestEitherDay() {
+	// the 1st or any monday, whichever is first
+	c.Equal(time.Date(2014, time.June, 30, 0, 0, 0, 0, time.UTC), c.next("0 0 1 * 1"))
+}
+
+func (c *CronTest) TestLists() {
+	c.Equal(time.Date(2014, time.June, 25, 10, 30, 0, 0, time.UTC), c.next("0,30 * * * *"))
+}
+
+func (c *CronTest) TestInvalid() {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every", "@every -5m"} {
+		_, err := Parse(spec)
+		c.Error(err, "should reject '"+spec+"'")
+	}
+}
@@ -0,0 +1,158 @@
+// Package schedule submits Where's it Up jobs on cron or interval schedules
+// and hands the finished jobs to sinks.
+package schedule
+
+import (
+	"context"
+	"github.com/ellotheth/gowup"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Client is the part of gowup.WIU the scheduler needs.
+type Client interface {
+	Submit(req *gowup.JobRequest) (gowup.JobID, error)
+	Wait(ctx context.Context, id gowup.JobID, interval time.Duration) (*gowup.Job, error)
+}
+
+// Check is a job to submit on a schedule. Name identifies it in the saved
+// state, so it should be unique and stable.
+type Check struct {
+	Name     string
+	Request  gowup.JobRequest
+	Schedule Schedule
+}
+
+// Sink receives every finished job.
+type Sink interface {
+	Send(check Check, id gowup.JobID, job *gowup.Job) error
+}
+
+// SinkFunc turns a function into a Sink.
+type SinkFunc func(check Check, id gowup.JobID, job *gowup.Job) error
+
+func (f SinkFunc) Send(check Check, id gowup.JobID, job *gowup.Job) error {
+	return f(check, id, job)
+}
+
+// Scheduler runs checks on their schedules. Jitter delays each run by a
+// random amount up to the given duration, so checks on the same schedule
+// don't all hit the API at once. A run that's still waiting on its job when
+// the next one is due makes the next one get skipped. Timeout is how long a
+// run waits for its job, and Poll is how often it checks on it.
+type Scheduler struct {
+	Client  Client
+	Checks  []Check
+	Sinks   []Sink
+	State   State
+	Jitter  time.Duration
+	Timeout time.Duration
+	Poll    time.Duration
+
+	running sync.Map
+	wg      sync.WaitGroup
+}
+
+// Run schedules every check until ctx is done, then waits for any runs in
+// progress to finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.State == nil {
+		s.State = NewMemoryState()
+	}
+	if s.Timeout == 0 {
+		s.Timeout = 10 * time.Minute
+	}
+	if s.Poll == 0 {
+		s.Poll = 10 * time.Second
+	}
+
+	var loops sync.WaitGroup
+	for _, check := range s.Checks {
+		loops.Add(1)
+		go func(check Check) {
+			defer loops.Done()
+			s.loop(ctx, check)
+		}(check)
+	}
+
+	loops.Wait()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, check Check) {
+	// pick up from the last run if there was one; anything missed while we
+	// were down runs once, right away
+	next := check.Schedule.Next(time.Now())
+	if last, ok := s.State.LastRun(check.Name); ok {
+		next = check.Schedule.Next(last)
+	}
+
+	for {
+		if next.IsZero() {
+			log.Printf("%s: schedule never fires again", check.Name)
+			return
+		}
+
+		delay := time.Until(next)
+		if s.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(s.Jitter)))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.start(ctx, check)
+		next = check.Schedule.Next(time.Now())
+	}
+}
+
+// start kicks off a run unless the last one is still going.
+func (s *Scheduler) start(ctx context.Context, check Check) {
+	if _, busy := s.running.LoadOrStore(check.Name, true); busy {
+		log.Printf("%s: previous run still in progress, skipping", check.Name)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.running.Delete(check.Name)
+		s.run(ctx, check)
+	}()
+}
+
+func (s *Scheduler) run(ctx context.Context, check Check) {
+	started := time.Now()
+	if err := s.State.SetLastRun(check.Name, started); err != nil {
+		log.Printf("%s: couldn't save state: %s", check.Name, err)
+	}
+
+	req := check.Request
+	id, err := s.Client.Submit(&req)
+	if err != nil {
+		log.Printf("%s: submit failed: %s", check.Name, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	job, err := s.Client.Wait(ctx, id, s.Poll)
+	if err != nil {
+		log.Printf("%s: job %s didn't finish: %s", check.Name, id, err)
+		return
+	}
+
+	for _, sink := range s.Sinks {
+		if err := sink.Send(check, id, job); err != nil {
+			log.Printf("%s: sink failed for job %s: %s", check.Name, id, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"github.com/ellotheth/gowup"
+	"github.com/stretchr/testify/suite"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClient finishes every job straight away, or once release is closed if
+// it's set.
+type fakeClient struct {
+	sync.Mutex
+	submitted int
+	release   chan struct{}
+	err       error
+}
+
+func (f *fakeClient) Submit(req *gowup.JobRequest) (gowup.JobID, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.submitted++
+	if f.err != nil {
+		return "", f.err
+	}
+	return "534419e98c3dcffa6170aeae", nil
+}
+
+func (f *fakeClient) Wait(ctx context.Context, id gowup.JobID, interval time.Duration) (*gowup.Job, error) {
+	if f.release == nil {
+		return &gowup.Job{}, nil
+	}
+
+	select {
+	case <-f.release:
+		return &gowup.Job{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeClient) count() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.submitted
+}
+
+type ScheduleTest struct {
+	suite.Suite
+	client *fakeClient
+}
+
+func TestSchedule(t *testing.T) {
+	suite.Run(t, new(ScheduleTest))
+}
+
+func (s *ScheduleTest) SetupTest() {
+	s.client = &fakeClient{}
+}
+
+// start runs the scheduler in the background. stop ends it and waits for it
+// to finish.
+func (s *ScheduleTest) start(scheduler *Scheduler) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *ScheduleTest) TestRunsAndSinks() {
+	var mu sync.Mutex
+	sent := 0
+	sink := SinkFunc(func(check Check, id gowup.JobID, job *gowup.Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent++
+		s.Equal("google", check.Name)
+		s.Equal(gowup.JobID("534419e98c3dcffa6170aeae"), id)
+		return nil
+	})
+
+	sends := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent
+	}
+
+	scheduler := &Scheduler{
+		Client: s.client,
+		Checks: []Check{{Name: "google", Schedule: Every(time.Millisecond)}},
+		Sinks:  []Sink{sink},
+	}
+
+	stop := s.start(scheduler)
+	s.Eventually(func() bool { return sends() >= 3 }, 5*time.Second, time.Millisecond, "should run on the schedule and send each job to the sinks")
+	stop()
+
+	// a run cut off by the stop submits without sending
+	s.GreaterOrEqual(s.client.count(), sends())
+	s.LessOrEqual(s.client.count(), sends()+1, "should send every finished job to the sinks")
+
+	_, ok := scheduler.State.LastRun("google")
+	s.True(ok, "should record the last run")
+}
+
+func (s *ScheduleTest) TestSkipsOverlappingRuns() {
+	s.client.release = make(chan struct{})
+
+	scheduler := &Scheduler{
+		Client: s.client,
+		Checks: []Check{{Name: "slow", Schedule: Every(time.Millisecond)}},
+	}
+
+	stop := s.start(scheduler)
+	defer stop()
+
+	s.Eventually(func() bool { return s.client.count() >= 1 }, 5*time.Second, time.Millisecond)
+	// the first run can't finish, so however many times the schedule fires
+	// in the meantime, nothing else gets submitted
+	time.Sleep(20 * time.Millisecond)
+	s.Equal(1, s.client.count(), "should skip runs while one is in progress")
+
+	close(s.client.release)
+	s.Eventually(func() bool { return s.client.count() >= 2 }, 5*time.Second, time.Millisecond, "should run again once the first run finishes")
+}
+
+func (s *ScheduleTest) TestResumesFromState() {
+	state := NewMemoryState()
+	state.SetLastRun("hourly", time.Now().Add(-2*time.Hour))
+
+	scheduler := &Scheduler{
+		Client: s.client,
+		Checks: []Check{{Name: "hourly", Schedule: Every(time.Hour)}},
+		State:  state,
+	}
+
+	stop := s.start(scheduler)
+	s.Eventually(func() bool { return s.client.count() >= 1 }, 5*time.Second, time.Millisecond, "should catch up on a missed run")
+	stop()
+
+	s.Equal(1, s.client.count(), "should catch up on a missed run once")
+}
+
+func (s *ScheduleTest) TestSubmitFailure() {
+	s.client.err = errors.New("nope")
+	sink := SinkFunc(func(check Check, id gowup.JobID, job *gowup.Job) error {
+		s.Fail("should not send failed jobs")
+		return nil
+	})
+
+	scheduler := &Scheduler{
+		Client: s.client,
+		Checks: []Check{{Name: "broken", Schedule: Every(20 * time.Millisecond)}},
+		Sinks:  []Sink{sink},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	scheduler.Run(ctx)
+}
+
+func (s *ScheduleTest) TestFileState() {
+	path := filepath.Join(s.T().TempDir(), "state.json")
+	when := time.Date(2014, time.June, 25, 10, 0, 0, 0, time.UTC)
+
+	state, err := NewFileState(path)
+	s.NoError(err, "should start empty without a file")
+	s.NoError(state.SetLastRun("google", when))
+
+	loaded, err := NewFileState(path)
+	s.NoError(err, "should load the saved file")
+	last, ok := loaded.LastRun("google")
+	s.True(ok)
+	s.True(when.Equal(last), "should round trip the last run")
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"encoding/json"
+	"github.com/ellotheth/gowup/internal/atomicfile"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// State remembers when each check last ran, so a restarted scheduler picks up
+// where it left off instead of running everything at once.
+type State interface {
+	LastRun(name string) (time.Time, bool)
+	SetLastRun(name string, t time.Time) error
+}
+
+// MemoryState keeps last run times in memory only.
+type MemoryState struct {
+	sync.Mutex
+	runs map[string]time.Time
+}
+
+func NewMemoryState() *MemoryState {
+	return &MemoryState{runs: map[string]time.Time{}}
+}
+
+func (m *MemoryState) LastRun(name string) (time.Time, bool) {
+	m.Lock()
+	defer m.Unlock()
+	t, ok := m.runs[name]
+	return t, ok
+}
+
+func (m *MemoryState) SetLastRun(name string, t time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	m.runs[name] = t
+	return nil
+}
+
+// FileState keeps last run times in a JSON file, rewritten on every update.
+type FileState struct {
+	MemoryState
+	path string
+}
+
+// NewFileState loads the state in path. A missing file is an empty state.
+func NewFileState(path string) (*FileState, error) {
+	f := &FileState{MemoryState: MemoryState{runs: map[string]time.Time{}}, path: path}
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &f.runs); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *FileState) SetLastRun(name string, t time.Time) error {
+	f.Lock()
+	defer f.Unlock()
+	f.runs[name] = t
+
+	raw, err := json.MarshalIndent(f.runs, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(f.path, raw, 0600)
+}
@@ -0,0 +1,220 @@
+package gowup
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats summarizes a set of latency samples, in milliseconds.
+type Stats struct {
+	Samples int
+	Min     float64
+	Avg     float64
+	Max     float64
+	StdDev  float64
+}
+
+// Percentiles across every location in a job.
+type Percentiles struct {
+	P50 float64
+	P90 float64
+	P99 float64
+}
+
+// LocationLatency pairs a location name with its latency stats, for when the
+// order matters.
+type LocationLatency struct {
+	Location string
+	Stats
+}
+
+// NewStats summarizes samples. Empty samples give zero stats.
+func NewStats(samples []float64) Stats {
+	stats := Stats{Samples: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = samples[0], samples[0]
+	sum := 0.0
+	for _, sample := range samples {
+		stats.Min = math.Min(stats.Min, sample)
+		stats.Max = math.Max(stats.Max, sample)
+		sum += sample
+	}
+	stats.Avg = sum / float64(len(samples))
+
+	variance := 0.0
+	for _, sample := range samples {
+		variance += (sample - stats.Avg) * (sample - stats.Avg)
+	}
+	stats.StdDev = math.Sqrt(variance / float64(len(samples)))
+
+	return stats
+}
+
+// Median is the middle sample, or the average of the middle two. Unlike
+// Percentile(samples, 50) it doesn't pick a side when the count is even.
+func Median(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// Percentile uses the nearest rank method. p is between 0 and 100.
+func Percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+// Latencies collects the latency samples for test ("ping" or "http") from
+// every location that finished it. Ping samples are the individual replies;
+// HTTP gets one sample per location, the time for the whole redirect chain.
+func (j Job) Latencies(test string) (map[string][]float64, error) {
+	samples := map[string][]float64{}
+
+	switch test {
+	case "ping":
+		results, err := j.PingResults()
+		if err != nil {
+			return nil, err
+		}
+		for city, result := range results {
+			if len(result.Times) > 0 {
+				samples[city] = result.Times
+			} else if result.Received > 0 {
+				samples[city] = []float64{result.Avg}
+			}
+		}
+	case "http":
+		results, err := j.HttpResults()
+		if err != nil {
+			return nil, err
+		}
+		for city, result := range results {
+			if len(result) > 0 {
+				samples[city] = []float64{result.Time()}
+			}
+		}
+	default:
+		return nil, &Error{msg: "No latency data for test '" + test + "'"}
+	}
+
+	return samples, nil
+}
+
+// LocationStats gets min/avg/max/stddev for test at each location.
+func (j Job) LocationStats(test string) (map[string]Stats, error) {
+	samples, err := j.Latencies(test)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]Stats{}
+	for city, times := range samples {
+		stats[city] = NewStats(times)
+	}
+
+	return stats, nil
+}
+
+// Percentiles of the average latency for test at each location, so every
+// location counts once no matter how many samples it sent back.
+func (j Job) Percentiles(test string) (Percentiles, error) {
+	stats, err := j.LocationStats(test)
+	if err != nil {
+		return Percentiles{}, err
+	}
+
+	averages := make([]float64, 0, len(stats))
+	for _, s := range stats {
+		averages = append(averages, s.Avg)
+	}
+
+	return Percentiles{
+		P50: Percentile(averages, 50),
+		P90: Percentile(averages, 90),
+		P99: Percentile(averages, 99),
+	}, nil
+}
+
+// ContinentStats rolls the latency samples for test up by continent, using
+// the catalog from Locations() to find out where each location is. Locations
+// missing from the catalog end up under "Unknown".
+func (j Job) ContinentStats(test string, locations []Location) (map[string]Stats, error) {
+	samples, err := j.Latencies(test)
+	if err != nil {
+		return nil, err
+	}
+
+	continents := map[string]string{}
+	for _, location := range locations {
+		continents[location.Name] = location.Continent
+	}
+
+	grouped := map[string][]float64{}
+	for city, times := range samples {
+		continent, ok := continents[city]
+		if !ok || continent == "" {
+			continent = "Unknown"
+		}
+		grouped[continent] = append(grouped[continent], times...)
+	}
+
+	stats := map[string]Stats{}
+	for continent, times := range grouped {
+		stats[continent] = NewStats(times)
+	}
+
+	return stats, nil
+}
+
+// WorstLocations ranks locations by average latency for test, slowest first.
+// n limits the number of locations returned; 0 or less returns all of them.
+func (j Job) WorstLocations(test string, n int) ([]LocationLatency, error) {
+	stats, err := j.LocationStats(test)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]LocationLatency, 0, len(stats))
+	for city, s := range stats {
+		ranked = append(ranked, LocationLatency{Location: city, Stats: s})
+	}
+
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].Avg == ranked[b].Avg {
+			return ranked[a].Location < ranked[b].Location
+		}
+		return ranked[a].Avg > ranked[b].Avg
+	})
+
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+
+	return ranked, nil
+}
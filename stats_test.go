@@ -0,0 +1,102 @@
+package gowup
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type StatsTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestStats(t *testing.T) {
+	suite.Run(t, new(StatsTest))
+}
+
+func (s *StatsTest) SetupTest() {
+	s.job = Job{Details: JobDetails{Done: JobDetail{
+		"denver": {
+			"ping": map[string]interface{}{"received": 4, "times": []interface{}{10, 20, 30, 40}},
+			"http": []interface{}{map[string]interface{}{"status": 200, "time": 90}},
+		},
+		"sydney": {
+			"ping": map[string]interface{}{"received": 1, "avg": 200},
+		},
+		"riga": {
+			"ping": map[string]interface{}{"received": 2, "times": []interface{}{50, 70}},
+		},
+		"tokyo": {
+			"ping": map[string]interface{}{"received": 0, "loss": 100},
+		},
+	}}}
+}
+
+func (s *StatsTest) TestNewStats() {
+	stats := NewStats([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	s.Equal(8, stats.Samples)
+	s.Equal(float64(2), stats.Min, "should find the minimum")
+	s.Equal(float64(9), stats.Max, "should find the maximum")
+	s.Equal(float64(5), stats.Avg, "should find the mean")
+	s.Equal(float64(2), stats.StdDev, "should find the standard deviation")
+
+	s.Equal(Stats{}, NewStats(nil), "should handle no samples")
+}
+
+func (s *StatsTest) TestPercentile() {
+	samples := []float64{15, 20, 35, 40, 50}
+	s.Equal(float64(35), Percentile(samples, 50))
+	s.Equal(float64(50), Percentile(samples, 90))
+	s.Equal(float64(15), Percentile(samples, 0))
+	s.Equal(float64(0), Percentile(nil, 50), "should handle no samples")
+}
+
+func (s *StatsTest) TestMedian() {
+	s.Equal(float64(35), Median([]float64{50, 15, 35, 20, 40}))
+	s.Equal(float64(27.5), Median([]float64{40, 15, 35, 20}), "should average the middle two")
+	s.Equal(float64(0), Median(nil), "should handle no samples")
+}
+
+func (s *StatsTest) TestLocationStats() {
+	stats, err := s.job.LocationStats("ping")
+	s.NoError(err, "should not return an error")
+	s.Equal(3, len(stats), "should skip locations with no replies")
+	s.Equal(float64(25), stats["denver"].Avg, "should average the reply times")
+	s.Equal(float64(200), stats["sydney"].Avg, "should fall back to the summary average")
+
+	stats, err = s.job.LocationStats("http")
+	s.NoError(err, "should not return an error")
+	s.Equal(float64(90), stats["denver"].Avg, "should use http timing")
+}
+
+func (s *StatsTest) TestUnknownTest() {
+	_, err := s.job.LocationStats("fast")
+	s.Error(err, "should reject tests without latency data")
+}
+
+func (s *StatsTest) TestPercentiles() {
+	p, err := s.job.Percentiles("ping")
+	s.NoError(err, "should not return an error")
+	s.Equal(Percentiles{P50: 60, P90: 200, P99: 200}, p)
+}
+
+func (s *StatsTest) TestContinentStats() {
+	locations := []Location{
+		{Name: "denver", Continent: "North America"},
+		{Name: "riga", Continent: "Europe"},
+	}
+
+	stats, err := s.job.ContinentStats("ping", locations)
+	s.NoError(err, "should not return an error")
+	s.Equal(float64(60), stats["Europe"].Avg, "should roll up by continent")
+	s.Equal(4, stats["North America"].Samples, "should include every sample")
+	s.Equal(float64(200), stats["Unknown"].Avg, "should group uncatalogued locations")
+}
+
+func (s *StatsTest) TestWorstLocations() {
+	ranked, err := s.job.WorstLocations("ping", 2)
+	s.NoError(err, "should not return an error")
+	s.Equal(2, len(ranked), "should limit the ranking")
+	s.Equal("sydney", ranked[0].Location, "should rank the slowest first")
+	s.Equal("riga", ranked[1].Location)
+}
@@ -0,0 +1,210 @@
+package gowup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans. It's shaped so an OpenTelemetry tracer is a few lines
+// of adapter away, without the package depending on it.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced operation.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Meter records metrics.
+type Meter interface {
+	// Add increments a counter.
+	Add(name string, value int64, attrs map[string]string)
+	// Record adds a value to a histogram.
+	Record(name string, value float64, attrs map[string]string)
+}
+
+// Telemetry instruments every API call: Locations, Jobs, Job, Submit and
+// Wait each get a span named after them ("wup.Submit"), and these metrics:
+//
+//	wup.client.calls        counter, by operation and outcome (ok/error)
+//	wup.client.errors       counter, by operation and error class
+//	wup.client.duration_ms  histogram, by operation
+//
+// Error classes are client (bad arguments or an unexpected response),
+// transport, decode, timeout and canceled. Either field can be nil.
+type Telemetry struct {
+	Tracer Tracer
+	Meter  Meter
+}
+
+// operation is an instrumented call in progress.
+type operation struct {
+	name      string
+	telemetry *Telemetry
+	span      Span
+	start     time.Time
+}
+
+func (api WIU) startOperation(ctx context.Context, name string) (context.Context, *operation) {
+	op := &operation{name: name, telemetry: api.Telemetry, start: time.Now()}
+	if op.telemetry != nil && op.telemetry.Tracer != nil {
+		ctx, op.span = op.telemetry.Tracer.Start(ctx, "wup."+name)
+	}
+	return ctx, op
+}
+
+func (op *operation) set(key string, value interface{}) {
+	if op.span != nil {
+		op.span.SetAttribute(key, value)
+	}
+}
+
+func (op *operation) finish(err error) {
+	if op.span != nil {
+		if err != nil {
+			op.span.RecordError(err)
+		}
+		op.span.End()
+	}
+
+	if op.telemetry == nil || op.telemetry.Meter == nil {
+		return
+	}
+
+	meter := op.telemetry.Meter
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		meter.Add("wup.client.errors", 1, map[string]string{"operation": op.name, "class": errorClass(err)})
+	}
+	meter.Add("wup.client.calls", 1, map[string]string{"operation": op.name, "outcome": outcome})
+	meter.Record("wup.client.duration_ms", float64(time.Since(op.start))/float64(time.Millisecond), map[string]string{"operation": op.name})
+}
+
+func errorClass(err error) string {
+	var syntax *json.SyntaxError
+	var unmarshal *json.UnmarshalTypeError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &syntax), errors.As(err, &unmarshal):
+		return "decode"
+	case errors.As(err, &netErr):
+		return "transport"
+	}
+
+	if _, ok := err.(*Error); ok {
+		return "client"
+	}
+	return "transport"
+}
+
+// MemoryTracer keeps every span in memory, for tests.
+type MemoryTracer struct {
+	sync.Mutex
+	Spans []*MemorySpan
+}
+
+// MemorySpan is a span recorded by MemoryTracer. Parent is the span that was
+// in the context it started from, if any.
+type MemorySpan struct {
+	Name       string
+	Parent     *MemorySpan
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+	tracer     *MemoryTracer
+}
+
+type spanKey struct{}
+
+func (t *MemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &MemorySpan{Name: name, Attributes: map[string]interface{}{}, tracer: t}
+	span.Parent, _ = ctx.Value(spanKey{}).(*MemorySpan)
+
+	t.Lock()
+	t.Spans = append(t.Spans, span)
+	t.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *MemorySpan) SetAttribute(key string, value interface{}) {
+	s.tracer.Lock()
+	defer s.tracer.Unlock()
+	s.Attributes[key] = value
+}
+
+func (s *MemorySpan) RecordError(err error) {
+	s.tracer.Lock()
+	defer s.tracer.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *MemorySpan) End() {
+	s.tracer.Lock()
+	defer s.tracer.Unlock()
+	s.Ended = true
+}
+
+// MemoryMeter keeps every metric in memory, for tests. Series are keyed by
+// name and attributes, like name{a=1,b=2}.
+type MemoryMeter struct {
+	sync.Mutex
+	Counters   map[string]int64
+	Histograms map[string][]float64
+}
+
+func NewMemoryMeter() *MemoryMeter {
+	return &MemoryMeter{Counters: map[string]int64{}, Histograms: map[string][]float64{}}
+}
+
+func (m *MemoryMeter) Add(name string, value int64, attrs map[string]string) {
+	m.Lock()
+	defer m.Unlock()
+	m.Counters[seriesKey(name, attrs)] += value
+}
+
+func (m *MemoryMeter) Record(name string, value float64, attrs map[string]string) {
+	m.Lock()
+	defer m.Unlock()
+	key := seriesKey(name, attrs)
+	m.Histograms[key] = append(m.Histograms[key], value)
+}
+
+// Counter gets the current value of a counter.
+func (m *MemoryMeter) Counter(name string, attrs map[string]string) int64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.Counters[seriesKey(name, attrs)]
+}
+
+// Histogram gets every value recorded in a histogram.
+func (m *MemoryMeter) Histogram(name string, attrs map[string]string) []float64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.Histograms[seriesKey(name, attrs)]
+}
+
+func seriesKey(name string, attrs map[string]string) string {
+	pairs := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
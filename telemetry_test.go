@@ -0,0 +1,108 @@
+package gowup
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type TelemetryTest struct {
+	suite.Suite
+	api    WIU
+	tracer *MemoryTracer
+	meter  *MemoryMeter
+}
+
+func TestTelemetry(t *testing.T) {
+	suite.Run(t, new(TelemetryTest))
+}
+
+func (t *TelemetryTest) SetupTest() {
+	t.tracer, t.meter = &MemoryTracer{}, NewMemoryMeter()
+	t.api = WIU{Client: "herp", Token: "derp", Telemetry: &Telemetry{Tracer: t.tracer, Meter: t.meter}}
+}
+
+func (t *TelemetryTest) TestSubmit() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobID": "534419e98c3dcffa6170aeae"}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	t.api.Submit(&JobRequest{Url: "https://google.com", Tests: []string{"ping"}, Locations: []string{"denver", "riga"}})
+
+	t.Equal(1, len(t.tracer.Spans))
+	span := t.tracer.Spans[0]
+	t.Equal("wup.Submit", span.Name)
+	t.True(span.Ended, "should end the span")
+	t.Equal("534419e98c3dcffa6170aeae", span.Attributes["wup.job_id"])
+	t.Equal([]string{"ping"}, span.Attributes["wup.tests"])
+	t.Equal(2, span.Attributes["wup.location_count"])
+
+	t.Equal(int64(1), t.meter.Counter("wup.client.calls", map[string]string{"operation": "Submit", "outcome": "ok"}))
+	t.Equal(1, len(t.meter.Histogram("wup.client.duration_ms", map[string]string{"operation": "Submit"})), "should record the latency")
+}
+
+func (t *TelemetryTest) TestErrorClasses() {
+	t.api.Submit(nil)
+	t.Equal(int64(1), t.meter.Counter("wup.client.errors", map[string]string{"operation": "Submit", "class": "client"}))
+	t.Equal(1, len(t.tracer.Spans[0].Errors), "should record the error on the span")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`NOPE`))
+	}))
+	apiEntryPoint = server.URL
+	t.api.Jobs()
+	t.Equal(int64(1), t.meter.Counter("wup.client.errors", map[string]string{"operation": "Jobs", "class": "decode"}))
+
+	server.Close()
+	t.api.Locations()
+	t.Equal(int64(1), t.meter.Counter("wup.client.errors", map[string]string{"operation": "Locations", "class": "transport"}))
+	t.Equal(int64(1), t.meter.Counter("wup.client.calls", map[string]string{"operation": "Locations", "outcome": "error"}))
+}
+
+func (t *TelemetryTest) TestWaitSpans() {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			w.Write([]byte(`{"response": {"complete": [], "error": [], "in_progress": {"denver": {"ping": {}}}}}`))
+			return
+		}
+		w.Write([]byte(`{"response": {"complete": {"denver": {"ping": {}}}, "error": [], "in_progress": []}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	t.api.Wait(context.Background(), "534419e98c3dcffa6170aeae", time.Millisecond)
+
+	t.Equal(3, len(t.tracer.Spans), "should trace the wait and every poll")
+	wait := t.tracer.Spans[0]
+	t.Equal("wup.Wait", wait.Name)
+	t.Equal(2, wait.Attributes["wup.polls"])
+	t.Equal("wup.Job", t.tracer.Spans[1].Name)
+	t.Equal(wait, t.tracer.Spans[1].Parent, "should nest polls under the wait")
+	t.Equal("534419e98c3dcffa6170aeae", t.tracer.Spans[2].Attributes["wup.job_id"])
+}
+
+func (t *TelemetryTest) TestTimeoutClass() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": {"complete": [], "error": [], "in_progress": {"denver": {"ping": {}}}}}`))
+	}))
+	defer server.Close()
+	apiEntryPoint = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	t.api.Wait(ctx, "534419e98c3dcffa6170aeae", time.Millisecond)
+
+	t.Equal(int64(1), t.meter.Counter("wup.client.errors", map[string]string{"operation": "Wait", "class": "timeout"}))
+}
+
+func (t *TelemetryTest) TestNoTelemetry() {
+	_, err := WIU{Telemetry: &Telemetry{}}.Submit(nil)
+	t.Error(err, "should work without a tracer or meter")
+}
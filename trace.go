@@ -0,0 +1,213 @@
+package gowup
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TraceGraph merges the traceroutes from every location in a job into one
+// graph. Hops that answered are merged by IP, so paths that converge share
+// nodes. Hops that timed out can't be matched up, so each gets its own node.
+type TraceGraph struct {
+	Target string
+	Nodes  map[string]*TraceNode
+	Edges  map[[2]string]*TraceEdge
+}
+
+// TraceNode is a source location, a hop, or the target. Sources lists the
+// locations whose traces pass through it.
+type TraceNode struct {
+	Id      string
+	Label   string
+	Kind    string
+	Sources []string
+}
+
+// Node kinds.
+const (
+	SourceNode  = "source"
+	HopNode     = "hop"
+	TimeoutNode = "timeout"
+	TargetNode  = "target"
+)
+
+// TraceEdge joins two consecutive nodes in a trace. Rtt has the average round
+// trip time to the To node from every location that took this edge.
+type TraceEdge struct {
+	From    string
+	To      string
+	Rtt     []float64
+	Sources []string
+	Reached bool
+}
+
+// TraceGraph builds the merged graph for every finished trace in the job. The
+// target is the job's IP address, or the URL's host if there isn't one.
+func (j Job) TraceGraph() (*TraceGraph, error) {
+	traces, err := j.TraceResults()
+	if err != nil {
+		return nil, err
+	}
+
+	target := j.traceTarget()
+	g := &TraceGraph{Target: target, Nodes: map[string]*TraceNode{}, Edges: map[[2]string]*TraceEdge{}}
+	if target != "" {
+		g.node(target, target, TargetNode, "")
+	}
+
+	cities := make([]string, 0, len(traces))
+	for city := range traces {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+
+	for _, city := range cities {
+		previous := "source:" + city
+		g.node(previous, city, SourceNode, city)
+
+		for i, hop := range traces[city] {
+			id, label, kind := hop.Ip, hop.Ip, HopNode
+			if hop.Host != "" && hop.Host != hop.Ip {
+				label = hop.Host + "\n" + hop.Ip
+			}
+			if hop.Ip == target {
+				kind = TargetNode
+			}
+			if !hop.Responded() {
+				id, label, kind = fmt.Sprintf("timeout:%s:%d", city, i), "*", TimeoutNode
+			}
+
+			g.node(id, label, kind, city)
+			g.edge(previous, id, city, hop.Avg(), true)
+			previous = id
+		}
+
+		// a trace that never got there still points at the target, so it's
+		// obvious where it gave up
+		if target != "" && previous != target {
+			g.edge(previous, target, city, 0, false)
+		}
+	}
+
+	return g, nil
+}
+
+// traceTarget is where the traces are headed: the job's IP address, or the
+// URL's host if there isn't one.
+func (j Job) traceTarget() string {
+	if j.Summary.Ip == "" && j.Summary.Url.URL != nil {
+		return j.Summary.Url.Hostname()
+	}
+	return j.Summary.Ip
+}
+
+func (g *TraceGraph) node(id, label, kind, source string) {
+	n, ok := g.Nodes[id]
+	if !ok {
+		n = &TraceNode{Id: id, Label: label, Kind: kind}
+		g.Nodes[id] = n
+	}
+	if source != "" && !contains(n.Sources, source) {
+		n.Sources = append(n.Sources, source)
+	}
+}
+
+func (g *TraceGraph) edge(from, to, source string, rtt float64, reached bool) {
+	key := [2]string{from, to}
+	e, ok := g.Edges[key]
+	if !ok {
+		e = &TraceEdge{From: from, To: to}
+		g.Edges[key] = e
+	}
+	if reached {
+		e.Reached = true
+		if rtt > 0 {
+			e.Rtt = append(e.Rtt, rtt)
+		}
+	}
+	if !contains(e.Sources, source) {
+		e.Sources = append(e.Sources, source)
+	}
+}
+
+// Dot renders the graph in Graphviz DOT format. Sources are boxes at the
+// top, the target is a double circle, timeouts are grey, and edges are
+// labelled with the average RTT to the next hop. Edges to the target from
+// traces that never reached it are dashed.
+func (g *TraceGraph) Dot(w io.Writer) error {
+	var out strings.Builder
+	out.WriteString("digraph trace {\n\trankdir=TB;\n\tnode [shape=ellipse];\n")
+
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		n := g.Nodes[id]
+		attrs := "label=" + quote(n.Label)
+		switch n.Kind {
+		case SourceNode:
+			attrs += ", shape=box, style=filled, fillcolor=lightblue"
+		case TargetNode:
+			attrs += ", shape=doublecircle, style=filled, fillcolor=palegreen"
+		case TimeoutNode:
+			attrs += ", style=dashed, fontcolor=grey, color=grey"
+		}
+		fmt.Fprintf(&out, "\t%s [%s];\n", quote(id), attrs)
+	}
+
+	sources := []string{}
+	for _, id := range ids {
+		if g.Nodes[id].Kind == SourceNode {
+			sources = append(sources, quote(id))
+		}
+	}
+	if len(sources) > 0 {
+		fmt.Fprintf(&out, "\t{ rank=source; %s; }\n", strings.Join(sources, "; "))
+	}
+
+	keys := make([][2]string, 0, len(g.Edges))
+	for key := range g.Edges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a][0] != keys[b][0] {
+			return keys[a][0] < keys[b][0]
+		}
+		return keys[a][1] < keys[b][1]
+	})
+
+	for _, key := range keys {
+		e := g.Edges[key]
+		attrs := []string{}
+		if len(e.Rtt) > 0 {
+			attrs = append(attrs, "label="+quote(strconv.FormatFloat(NewStats(e.Rtt).Avg, 'f', 1, 64)+" ms"))
+		}
+		if !e.Reached {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(e.Sources) > 1 {
+			attrs = append(attrs, "penwidth="+strconv.Itoa(len(e.Sources)))
+		}
+
+		line := fmt.Sprintf("\t%s -> %s", quote(e.From), quote(e.To))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		out.WriteString(line + ";\n")
+	}
+
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
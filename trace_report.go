@@ -0,0 +1,142 @@
+package gowup
+
+import (
+	"sort"
+)
+
+// a hop that adds at least this much latency (in ms) counts as a jump
+const traceJump = 30.0
+
+// TraceReport analyzes the trace from every location in a job.
+type TraceReport struct {
+	Target string
+	Paths  map[string]PathReport
+	Shared []SharedHop
+}
+
+// PathReport is the analysis of the trace from one location.
+//
+// Bottleneck is the hop that added the most latency, if any hop added at
+// least 30ms. LossStart is the hop where probes started timing out and never
+// recovered, if the trace ends that way; timeouts in the middle of a trace
+// that recovers are usually just routers ignoring probes. Loops lists IPs
+// that show up more than once.
+type PathReport struct {
+	Location   string
+	Hops       []HopReport
+	Reached    bool
+	Timeouts   int
+	Bottleneck *HopReport
+	LossStart  *HopReport
+	Loops      []string
+}
+
+// HopReport is one hop with its analysis. Increment is how much latency the
+// hop added over the last hop that responded. Loss is the percentage of
+// probes that timed out.
+type HopReport struct {
+	TraceHop
+	Increment float64
+	Loss      float64
+	Jump      bool
+}
+
+// SharedHop is a hop that traces from more than one location pass through.
+type SharedHop struct {
+	Ip      string
+	Host    string
+	Sources []string
+}
+
+// TraceReport analyzes every finished trace in the job.
+func (j Job) TraceReport() (*TraceReport, error) {
+	traces, err := j.TraceResults()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TraceReport{Target: j.traceTarget(), Paths: map[string]PathReport{}}
+	shared := map[string]*SharedHop{}
+
+	for city, trace := range traces {
+		path := analyzePath(city, trace, report.Target)
+		report.Paths[city] = path
+
+		for _, hop := range path.Hops {
+			if !hop.Responded() || hop.Ip == report.Target {
+				continue
+			}
+			s, ok := shared[hop.Ip]
+			if !ok {
+				s = &SharedHop{Ip: hop.Ip, Host: hop.Host}
+				shared[hop.Ip] = s
+			}
+			if !contains(s.Sources, city) {
+				s.Sources = append(s.Sources, city)
+			}
+		}
+	}
+
+	for _, s := range shared {
+		if len(s.Sources) > 1 {
+			sort.Strings(s.Sources)
+			report.Shared = append(report.Shared, *s)
+		}
+	}
+	sort.Slice(report.Shared, func(a, b int) bool {
+		sa, sb := report.Shared[a], report.Shared[b]
+		if len(sa.Sources) != len(sb.Sources) {
+			return len(sa.Sources) > len(sb.Sources)
+		}
+		return sa.Ip < sb.Ip
+	})
+
+	return report, nil
+}
+
+func analyzePath(city string, trace TraceResult, target string) PathReport {
+	path := PathReport{Location: city, Hops: make([]HopReport, len(trace))}
+
+	seen := map[string]int{}
+	previous := 0.0
+	for i, hop := range trace {
+		h := HopReport{TraceHop: hop}
+
+		probes := len(hop.Rtt) + hop.Timeouts
+		if probes > 0 {
+			h.Loss = float64(hop.Timeouts) * 100 / float64(probes)
+		}
+
+		if !hop.Responded() {
+			h.Loss = 100
+			path.Timeouts++
+		} else {
+			h.Increment = hop.Avg() - previous
+			h.Jump = h.Increment >= traceJump
+			previous = hop.Avg()
+
+			seen[hop.Ip]++
+			if seen[hop.Ip] == 2 {
+				path.Loops = append(path.Loops, hop.Ip)
+			}
+			if hop.Ip == target {
+				path.Reached = true
+			}
+		}
+
+		path.Hops[i] = h
+	}
+
+	for i := range path.Hops {
+		if path.Hops[i].Jump && (path.Bottleneck == nil || path.Hops[i].Increment > path.Bottleneck.Increment) {
+			path.Bottleneck = &path.Hops[i]
+		}
+	}
+
+	// walk back from the end for as long as probes are dropping
+	for i := len(path.Hops) - 1; i >= 0 && path.Hops[i].Loss > 0; i-- {
+		path.LossStart = &path.Hops[i]
+	}
+
+	return path
+}
@@ -0,0 +1,90 @@
+package gowup
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TraceReportTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestTraceReport(t *testing.T) {
+	suite.Run(t, new(TraceReportTest))
+}
+
+func (t *TraceReportTest) SetupTest() {
+	t.job = Job{}
+	json.Unmarshal([]byte(`{
+	    "request": {"url": "https://google.com", "ip": "8.8.8.8"},
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "trace": {"summary": [
+	                    {"hop": 1, "ip": "10.0.0.1", "rtt": [1, 1, 1]},
+	                    {"hop": 2, "ip": "*", "timeouts": 3},
+	                    {"hop": 3, "ip": "4.4.4.4", "rtt": [10, 10, 10]},
+	                    {"hop": 4, "ip": "5.5.5.5", "rtt": [90, 90, 90]},
+	                    {"hop": 5, "ip": "8.8.8.8", "rtt": [95, 95, 95]}
+	                ]}
+	            },
+	            "riga": {
+	                "trace": {"summary": [
+	                    {"hop": 1, "ip": "192.168.1.1", "rtt": [2, 2, 2]},
+	                    {"hop": 2, "ip": "4.4.4.4", "rtt": [20, 20, 20]},
+	                    {"hop": 3, "ip": "6.6.6.6", "rtt": [30, 30], "timeouts": 1},
+	                    {"hop": 4, "ip": "*", "timeouts": 3},
+	                    {"hop": 5, "ip": "*", "timeouts": 3}
+	                ]}
+	            },
+	            "tokyo": {
+	                "trace": {"summary": [
+	                    {"hop": 1, "ip": "4.4.4.4", "rtt": [5, 5, 5]},
+	                    {"hop": 2, "ip": "7.7.7.7", "rtt": [8, 8, 8]},
+	                    {"hop": 3, "ip": "4.4.4.4", "rtt": [9, 9, 9]}
+	                ]}
+	            }
+	        },
+	        "error": [],
+	        "in_progress": []
+	    }
+	}`), &t.job)
+}
+
+func (t *TraceReportTest) TestIncrements() {
+	report, err := t.job.TraceReport()
+	t.NoError(err, "should not return an error")
+
+	denver := report.Paths["denver"]
+	t.True(denver.Reached, "should notice the target was reached")
+	t.Equal(1, denver.Timeouts, "should count timed out hops")
+	t.Equal(float64(9), denver.Hops[2].Increment, "should skip timeouts when working out increments")
+	t.Equal(float64(100), denver.Hops[1].Loss)
+	t.Nil(denver.LossStart, "should ignore timeouts the trace recovers from")
+
+	t.Equal("5.5.5.5", denver.Bottleneck.Ip, "should find the biggest latency jump")
+	t.Equal(float64(80), denver.Bottleneck.Increment)
+}
+
+func (t *TraceReportTest) TestLoss() {
+	report, _ := t.job.TraceReport()
+
+	riga := report.Paths["riga"]
+	t.False(riga.Reached)
+	t.Equal("6.6.6.6", riga.LossStart.Ip, "should find where packets start dropping")
+	t.InDelta(33.3, riga.Hops[2].Loss, 0.1, "should work out partial loss")
+	t.Nil(riga.Bottleneck, "should not report small jumps")
+}
+
+func (t *TraceReportTest) TestLoops() {
+	report, _ := t.job.TraceReport()
+	t.Equal([]string{"4.4.4.4"}, report.Paths["tokyo"].Loops, "should flag repeated hops")
+	t.Empty(report.Paths["denver"].Loops)
+}
+
+func (t *TraceReportTest) TestSharedHops() {
+	report, _ := t.job.TraceReport()
+	t.Equal([]SharedHop{{Ip: "4.4.4.4", Sources: []string{"denver", "riga", "tokyo"}}}, report.Shared, "should only list hops shared by several locations")
+}
@@ -0,0 +1,96 @@
+package gowup
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TraceTest struct {
+	suite.Suite
+	job Job
+}
+
+func TestTrace(t *testing.T) {
+	suite.Run(t, new(TraceTest))
+}
+
+func (t *TraceTest) SetupTest() {
+	t.job = Job{}
+	json.Unmarshal([]byte(`{
+	    "request": {"url": "https://google.com", "ip": "8.8.8.8"},
+	    "response": {
+	        "complete": {
+	            "denver": {
+	                "trace": {"summary": [
+	                    {"hop": 1, "ip": "10.0.0.1", "rtt": [1, 1, 1]},
+	                    {"hop": 2, "host": "core.example.net", "ip": "4.4.4.4", "rtt": [10, 12, 14]},
+	                    {"hop": 3, "ip": "8.8.8.8", "rtt": [20, 20, 20]}
+	                ]}
+	            },
+	            "riga": {
+	                "trace": {"summary": [
+	                    {"hop": 1, "ip": "192.168.1.1", "rtt": [2]},
+	                    {"hop": 2, "ip": "*", "timeouts": 3},
+	                    {"hop": 3, "host": "core.example.net", "ip": "4.4.4.4", "rtt": [30]}
+	                ]}
+	            }
+	        },
+	        "error": [],
+	        "in_progress": []
+	    }
+	}`), &t.job)
+}
+
+func (t *TraceTest) TestGraph() {
+	g, err := t.job.TraceGraph()
+	t.NoError(err, "should not return an error")
+	t.Equal("8.8.8.8", g.Target)
+
+	t.Equal(SourceNode, g.Nodes["source:denver"].Kind, "should add a root per location")
+	t.Equal(TargetNode, g.Nodes["8.8.8.8"].Kind, "should mark the target")
+	t.Equal(TimeoutNode, g.Nodes["timeout:riga:1"].Kind, "should keep timeouts apart")
+	t.Equal([]string{"denver", "riga"}, g.Nodes["4.4.4.4"].Sources, "should merge shared hops")
+	t.Equal("core.example.net\n4.4.4.4", g.Nodes["4.4.4.4"].Label, "should label hops with hostnames")
+
+	edge := g.Edges[[2]string{"10.0.0.1", "4.4.4.4"}]
+	t.Equal([]float64{12}, edge.Rtt, "should annotate edges with the rtt")
+	t.True(edge.Reached)
+
+	unreached := g.Edges[[2]string{"4.4.4.4", "8.8.8.8"}]
+	t.Equal([]string{"denver", "riga"}, unreached.Sources, "should point unfinished traces at the target")
+	t.True(unreached.Reached, "should be reached if any location got there")
+}
+
+func (t *TraceTest) TestTargetFromUrl() {
+	t.job.Summary.Ip = ""
+
+	g, err := t.job.TraceGraph()
+	t.NoError(err, "should not return an error")
+	t.Equal("google.com", g.Target, "should fall back to the url host")
+}
+
+func (t *TraceTest) TestDot() {
+	g, _ := t.job.TraceGraph()
+
+	var out bytes.Buffer
+	t.NoError(g.Dot(&out))
+	dot := out.String()
+
+	t.Contains(dot, "digraph trace {\n")
+	t.Contains(dot, `"source:denver" [label="denver", shape=box`)
+	t.Contains(dot, `"8.8.8.8" [label="8.8.8.8", shape=doublecircle`)
+	t.Contains(dot, `"4.4.4.4" [label="core.example.net\n4.4.4.4"];`)
+	t.Contains(dot, `"source:denver" -> "10.0.0.1" [label="1.0 ms"];`)
+	t.Contains(dot, `"4.4.4.4" -> "8.8.8.8" [label="20.0 ms", penwidth=2];`)
+	t.Contains(dot, `"timeout:riga:1" [label="*", style=dashed`)
+	t.Contains(dot, "{ rank=source; \"source:denver\"; \"source:riga\"; }")
+}
+
+func (t *TraceTest) TestTraceHop() {
+	t.False(TraceHop{Ip: "*"}.Responded())
+	t.False(TraceHop{}.Responded())
+	t.Equal(float64(0), TraceHop{}.Avg())
+	t.Equal(float64(2), TraceHop{Ip: "1.1.1.1", Rtt: []float64{1, 3}}.Avg())
+}